@@ -0,0 +1,117 @@
+package aconf
+
+import (
+	"io"
+)
+
+// Config is a parsed HOCON document, whose values can be decoded into Go values as a whole or path by path.
+//
+// A large shared configuration can be parsed once and each component can then decode its own section with
+// Decode, using a struct which only mirrors that section. A Config is not changed by decoding it, so it can be
+// used by several goroutines at once.
+//
+// A field of type Config holds the subtree of its key as it is, to be decoded later, e.g. by a plugin which
+// defines its own settings.
+type Config struct {
+	root   *hoconNode
+	parser HoconParser
+	// path is the path of the root in the document it was decoded from, to report the full paths in errors
+	path string
+}
+
+// ParseConfig parses the HOCON read from the reader into a Config, which is decoded later using the options and
+// converters of this parser. Any syntax errors are returned, together with a nil Config.
+func (parser *HoconParser) ParseConfig(hoconContentReader io.Reader) (*Config, error) {
+	state := parser.newParseState()
+	root, err := state.parseTree(hoconContentReader)
+	if err != nil {
+		return nil, err
+	}
+	if root == nil {
+		root = newObjectNode(LexLocation{fileName: readerName(hoconContentReader), lineNumber: 1, columnNumber: 1})
+	}
+	return &Config{root, state.HoconParser, ""}, nil
+}
+
+// Decode decodes the value at the path expression, such as axlrate.imdg or servers.0, into v, which must be a
+// non-nil pointer. An empty path decodes the whole document.
+//
+// Errors report the full paths of the keys. If nothing is set at the path, a *ParserMissingKeyErr is returned.
+func (config *Config) Decode(path string, v interface{}) error {
+	node, fullPath, err := config.lookup(path)
+	if err != nil {
+		return err
+	}
+	return config.parser.newParseState().unmarshal(node, v, fullPath)
+}
+
+// Origin returns where the value at the path expression was set. An empty path gives the origin of the document.
+//
+// If nothing is set at the path, a *ParserMissingKeyErr is returned.
+func (config *Config) Origin(path string) (ConfigOrigin, error) {
+	node, _, err := config.lookup(path)
+	if err != nil {
+		return ConfigOrigin{}, err
+	}
+	return node.origin(), nil
+}
+
+// Keys returns the keys of the object at the root of the Config, in the order in which they were first set.
+// There are none if the root is not an object.
+func (config *Config) Keys() []string {
+	if config.root == nil {
+		return nil
+	}
+	return append([]string(nil), config.root.keys...)
+}
+
+// WithFallback returns a Config holding the values of this Config, along with the values of the fallback for the
+// keys which this Config does not set. Objects set in both are merged, key by key.
+// The values taken from the fallback have the origin kind OriginFallback, unless they are from an include or an
+// environment variable. Neither Config is changed.
+func (config *Config) WithFallback(fallback *Config) *Config {
+	root := config.root.clone()
+	if fallback != nil && fallback.root != nil {
+		layer := fallback.root.clone()
+		layer.setSource(OriginFallback)
+		switch {
+		case root == nil:
+			root = layer
+		case root.nodeType == objectNode && layer.nodeType == objectNode:
+			layer.merge(root)
+			layer.source, layer.LexLocation = root.source, root.LexLocation
+			root = layer
+		}
+	}
+	return &Config{root, config.parser, config.path}
+}
+
+// lookup returns the node at the path expression below the root, along with its full path in the document
+func (config *Config) lookup(path string) (*hoconNode, string, error) {
+	var elements []string
+	if path != "" {
+		var err error
+		if elements, err = splitPath(path); err != nil {
+			return nil, "", err
+		}
+	}
+	node, fullPath := config.root, config.path
+	for _, element := range elements {
+		if i, isIndex := arrayIndex(element); isIndex && node != nil && node.nodeType == arrayNode {
+			fullPath = indexPath(fullPath, i)
+		} else {
+			fullPath = joinPath(fullPath, element)
+		}
+		if node != nil {
+			node = node.get([]string{element})
+		}
+	}
+	if node == nil {
+		var location LexLocation
+		if config.root != nil {
+			location = config.root.LexLocation
+		}
+		return nil, "", &ParserMissingKeyErr{fullPath, location}
+	}
+	return node, fullPath, nil
+}
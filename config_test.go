@@ -0,0 +1,188 @@
+package aconf
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sharedConfig = `
+axlrate {
+  imdg {
+    name = axlrate-imdg
+    hosts = [10.0.0.1, 10.0.0.2]
+  }
+  web.port = 8080
+}
+servers = [{ host = a, port = 1 }, { host = b, port = x }]
+`
+
+type imdgConfig struct {
+	Name  string   `hocon:"name"`
+	Hosts []string `hocon:"hosts"`
+}
+
+func TestParsePath(t *testing.T) {
+	target := &imdgConfig{}
+	parser := &HoconParser{DisallowUnknownKeys: true}
+	if err := parser.ParsePath(strings.NewReader(sharedConfig), "axlrate.imdg", target); err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+	want := &imdgConfig{"axlrate-imdg", []string{"10.0.0.1", "10.0.0.2"}}
+	if !reflect.DeepEqual(target, want) {
+		t.Errorf("Got: %+v, Want : %+v", target, want)
+	}
+}
+
+func TestNilParser(t *testing.T) {
+	var parser *HoconParser
+	target := &imdgConfig{}
+	if err := parser.ParsePath(strings.NewReader(sharedConfig), "axlrate.imdg", target); err != nil || target.Name != "axlrate-imdg" {
+		t.Errorf("Got: %+v %v, Want : %v", target, err, "axlrate-imdg")
+	}
+	config, err := parser.ParseConfig(strings.NewReader(sharedConfig))
+	if err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+	var port int
+	if err := config.Decode("axlrate.web.port", &port); err != nil || port != 8080 {
+		t.Errorf("Got: %v %v, Want : %v", port, err, 8080)
+	}
+}
+
+func TestConfigDecode(t *testing.T) {
+	parser := &HoconParser{}
+	config, err := parser.ParseConfig(strings.NewReader(sharedConfig))
+	if err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+
+	var port int
+	if err := config.Decode(`axlrate."web".port`, &port); err != nil || port != 8080 {
+		t.Errorf("Got: %v %v, Want : %v", port, err, 8080)
+	}
+	type server struct {
+		Host string `hocon:"host"`
+		Port int    `hocon:"port"`
+	}
+	var first server
+	if err := config.Decode("servers.0", &first); err != nil || first != (server{"a", 1}) {
+		t.Errorf("Got: %+v %v, Want : %+v", first, err, server{"a", 1})
+	}
+	var all map[string]interface{}
+	if err := config.Decode("", &all); err != nil || len(all) != 2 {
+		t.Errorf("Got: %v %v, Want : axlrate and servers", all, err)
+	}
+
+	var mismatchErr *ParserTypeMismatchErr
+	if err := config.Decode("servers.1", &server{}); !errors.As(err, &mismatchErr) || mismatchErr.Path != "servers[1].port" {
+		t.Errorf("Expected : type mismatch for servers[1].port, Got : %v", err)
+	}
+	var missingErr *ParserMissingKeyErr
+	if err := config.Decode("axlrate.cache", &imdgConfig{}); !errors.As(err, &missingErr) || missingErr.Path != "axlrate.cache" {
+		t.Errorf("Expected : missing key axlrate.cache, Got : %v", err)
+	}
+	if err := config.Decode("servers.2.host", new(string)); !errors.Is(err, ErrMissingRequired) {
+		t.Errorf("Expected : %v, Got : %v", ErrMissingRequired, err)
+	}
+	var pathErr *ParserInvalidPathErr
+	if err := config.Decode("axlrate..imdg", &imdgConfig{}); !errors.As(err, &pathErr) {
+		t.Errorf("Expected : invalid path, Got : %v", err)
+	}
+}
+
+func TestRemainFields(t *testing.T) {
+	type Plugin struct {
+		Name     string                 `hocon:"name"`
+		Settings map[string]interface{} `hocon:",remain"`
+	}
+	type Component struct {
+		Name  string `hocon:"name"`
+		Extra Config `hocon:",remain"`
+	}
+	type TargetStruct struct {
+		Plugin    Plugin    `hocon:"plugin"`
+		Component Component `hocon:"component"`
+		Raw       Config    `hocon:"raw"`
+	}
+	contents := `
+	plugin { name = cache, size = 10, Settings = x, eviction { policy = lru } }
+	component { name = web, port = x }
+	raw { a = 1 }
+	`
+	target := &TargetStruct{}
+	parser := &HoconParser{DisallowUnknownKeys: true}
+	if err := parser.Parse(strings.NewReader(contents), target); err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+	want := Plugin{"cache", map[string]interface{}{
+		"size": int64(10), "Settings": "x", "eviction": map[string]interface{}{"policy": "lru"}}}
+	if !reflect.DeepEqual(target.Plugin, want) {
+		t.Errorf("Got: %+v, Want : %+v", target.Plugin, want)
+	}
+
+	var port struct {
+		Port int `hocon:"port"`
+	}
+	var mismatchErr *ParserTypeMismatchErr
+	if err := target.Component.Extra.Decode("", &port); !errors.As(err, &mismatchErr) || mismatchErr.Path != "component.port" {
+		t.Errorf("Expected : type mismatch for component.port, Got : %v", err)
+	}
+	var a int
+	if err := target.Raw.Decode("a", &a); err != nil || a != 1 {
+		t.Errorf("Got: %v %v, Want : %v", a, err, 1)
+	}
+	var unset Config
+	if err := unset.Decode("a", &a); !errors.Is(err, ErrMissingRequired) {
+		t.Errorf("Expected : %v, Got : %v", ErrMissingRequired, err)
+	}
+}
+
+func TestConfigOrigins(t *testing.T) {
+	parser := &HoconParser{}
+	defaults, err := parser.ParseConfig(&namedReader{strings.NewReader("db { host = localhost, port = 5432 }\nlevel = info"), "defaults.conf"})
+	if err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+	app, err := parser.ParseConfig(&namedReader{strings.NewReader("\ndb.host = db.example\nname = app\nalias = ${name}"), "app.conf"})
+	if err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+	config := app.WithFallback(defaults)
+
+	if keys := config.Keys(); !reflect.DeepEqual(keys, []string{"db", "level", "name", "alias"}) {
+		t.Errorf("Got: %v, Want : %v", keys, []string{"db", "level", "name", "alias"})
+	}
+	origins := map[string]string{
+		"db.host": "app.conf:2:11",
+		"db.port": "defaults.conf:1:31 (fallback)",
+		"level":   "defaults.conf:2:9 (fallback)",
+		"name":    "app.conf:3:8",
+		"alias":   "app.conf:4:9 (substitution)",
+	}
+	for path, want := range origins {
+		origin, err := config.Origin(path)
+		if err != nil || origin.String() != want {
+			t.Errorf("path: %s, Got: %v %v, Want : %v", path, origin, err, want)
+		}
+	}
+	var db struct {
+		Host string `hocon:"host"`
+		Port int    `hocon:"port"`
+	}
+	if err := config.Decode("db", &db); err != nil || db.Host != "db.example" || db.Port != 5432 {
+		t.Errorf("Got: %+v %v, Want : {Host:db.example Port:5432}", db, err)
+	}
+
+	// Neither layer is changed
+	if keys := app.Keys(); !reflect.DeepEqual(keys, []string{"db", "name", "alias"}) {
+		t.Errorf("Got: %v, Want : %v", keys, []string{"db", "name", "alias"})
+	}
+	if origin, _ := defaults.Origin("db.port"); origin.Kind != OriginFile {
+		t.Errorf("Got: %v, Want : %v", origin.Kind, OriginFile)
+	}
+	if _, err := config.Origin("db.user"); !errors.Is(err, ErrMissingRequired) {
+		t.Errorf("Expected : %v, Got : %v", ErrMissingRequired, err)
+	}
+}
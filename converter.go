@@ -0,0 +1,87 @@
+package aconf
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ValueType is the type of a HOCON value, as seen by converters and reported in type mismatch errors
+type ValueType int
+
+const (
+	StringValue ValueType = iota
+	NumberValue
+	BooleanValue
+	DurationValue
+	SizeValue
+	PeriodValue
+	ObjectValue
+	ArrayValue
+)
+
+var valueTypeNames = [...]string{
+	StringValue:   "string",
+	NumberValue:   "number",
+	BooleanValue:  "boolean",
+	DurationValue: "duration",
+	SizeValue:     "size",
+	PeriodValue:   "period",
+	ObjectValue:   "object",
+	ArrayValue:    "array",
+}
+
+func (t ValueType) String() string {
+	if t < 0 || int(t) >= len(valueTypeNames) {
+		return fmt.Sprintf("ValueType(%d)", int(t))
+	}
+	return valueTypeNames[t]
+}
+
+// ConverterFunc converts a HOCON value into a value of the type it was registered for.
+//
+// The value is passed as it would be decoded into an interface{}: a string, an int64 (numbers and sizes in bytes),
+// a float64, a bool, a time.Duration, a Period, a []interface{} for arrays or a map[string]interface{} for objects.
+// Numbers are passed as a Number with the UseNumber option.
+type ConverterFunc func(value interface{}) (interface{}, error)
+
+type converterKey struct {
+	from ValueType
+	to   reflect.Type
+}
+
+// RegisterConverter registers fn to decode values of type from into targets of type to, taking precedence over
+// the built-in decoding and over an Unmarshaler implemented by the target.
+// For example, registering a converter from StringValue to reflect.TypeOf((*regexp.Regexp)(nil)) decodes strings
+// into *regexp.Regexp fields, slice elements and map values.
+//
+// The value returned by fn must be assignable to the type to, otherwise the field is left unchanged and an error
+// is reported. Converters must be registered before the HoconParser is used.
+func (parser *HoconParser) RegisterConverter(from ValueType, to reflect.Type, fn ConverterFunc) {
+	if parser.converters == nil {
+		parser.converters = map[converterKey]ConverterFunc{}
+	}
+	parser.converters[converterKey{from, to}] = fn
+}
+
+// function convert decodes the node into v using the converter registered for their types.
+// The returned bool is false if there is no such converter
+func (parser *HoconParser) convert(node *hoconNode, v reflect.Value, path string) (bool, error) {
+	fn, ok := parser.converters[converterKey{node.valueType(), v.Type()}]
+	if !ok {
+		return false, nil
+	}
+	value, err := fn(node.interfaceValue(parser.UseNumber))
+	if err != nil {
+		return true, &ParserUnmarshalErr{path, err, node.LexLocation}
+	}
+	rv := reflect.ValueOf(value)
+	switch {
+	case !rv.IsValid():
+		v.Set(reflect.Zero(v.Type()))
+	case rv.Type().AssignableTo(v.Type()):
+		v.Set(rv)
+	default:
+		return true, &ParserUnmarshalErr{path, fmt.Errorf("converter returned %T, want %s", value, v.Type()), node.LexLocation}
+	}
+	return true, nil
+}
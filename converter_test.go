@@ -0,0 +1,109 @@
+package aconf
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"reflect"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"testing"
+)
+
+type stringSet map[string]struct{}
+
+func newConverterParser() *HoconParser {
+	parser := &HoconParser{}
+	parser.RegisterConverter(StringValue, reflect.TypeOf((*regexp.Regexp)(nil)), func(value interface{}) (interface{}, error) {
+		return regexp.Compile(value.(string))
+	})
+	parser.RegisterConverter(StringValue, reflect.TypeOf(slog.Level(0)), func(value interface{}) (interface{}, error) {
+		var level slog.Level
+		err := level.UnmarshalText([]byte(value.(string)))
+		return level, err
+	})
+	parser.RegisterConverter(StringValue, reflect.TypeOf(net.IPNet{}), func(value interface{}) (interface{}, error) {
+		_, ipNet, err := net.ParseCIDR(value.(string))
+		if err != nil {
+			return nil, err
+		}
+		return *ipNet, nil
+	})
+	parser.RegisterConverter(ArrayValue, reflect.TypeOf(stringSet{}), func(value interface{}) (interface{}, error) {
+		set := stringSet{}
+		for _, element := range value.([]interface{}) {
+			set[fmt.Sprint(element)] = struct{}{}
+		}
+		return set, nil
+	})
+	return parser
+}
+
+func TestRegisteredConverters(t *testing.T) {
+	type TargetStruct struct {
+		Pattern  *regexp.Regexp   `hocon:"pattern"`
+		Patterns []*regexp.Regexp `hocon:"patterns"`
+		Level    slog.Level       `hocon:"level"`
+		Network  net.IPNet        `hocon:"network"`
+		Roles    stringSet        `hocon:"roles"`
+		Levels   map[string]*slog.Level
+	}
+	contents := `
+	pattern = "^a+$"
+	patterns = ["b", "c+"]
+	level = WARN
+	network = "10.0.0.0/8"
+	roles = [admin, reader, admin]
+	Levels { db = DEBUG }
+	`
+	target := &TargetStruct{}
+	if err := newConverterParser().Parse(strings.NewReader(contents), target); err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+	if target.Pattern.String() != "^a+$" || len(target.Patterns) != 2 || target.Patterns[1].String() != "c+" {
+		t.Errorf("Got: %v %v, Want : ^a+$ [b c+]", target.Pattern, target.Patterns)
+	}
+	if target.Level != slog.LevelWarn || *target.Levels["db"] != slog.LevelDebug {
+		t.Errorf("Got: %v %v, Want : %v %v", target.Level, target.Levels, slog.LevelWarn, slog.LevelDebug)
+	}
+	if target.Network.String() != "10.0.0.0/8" {
+		t.Errorf("Got: %v, Want : %v", target.Network.String(), "10.0.0.0/8")
+	}
+	if want := (stringSet{"admin": {}, "reader": {}}); !reflect.DeepEqual(target.Roles, want) {
+		t.Errorf("Got: %v, Want : %v", target.Roles, want)
+	}
+}
+
+func TestConverterErrors(t *testing.T) {
+	type TargetStruct struct {
+		Pattern *regexp.Regexp `hocon:"pattern"`
+		Roles   stringSet      `hocon:"roles"`
+		Other   []int          `hocon:"other"`
+	}
+	parser := newConverterParser()
+	parser.RegisterConverter(ArrayValue, reflect.TypeOf([]int{}), func(value interface{}) (interface{}, error) {
+		return "not a slice", nil
+	})
+	contents := "pattern = \"a(\"\nroles = admin\nother = [1]"
+	err := parser.Parse(strings.NewReader(contents), &TargetStruct{})
+
+	var list ErrorList
+	if !errors.As(err, &list) || len(list) != 3 {
+		t.Fatalf("Expected : 3 errors, Got : %v", err)
+	}
+	var unmarshalErr *ParserUnmarshalErr
+	var syntaxErr *syntax.Error
+	if !errors.As(list[0], &unmarshalErr) || unmarshalErr.Path != "pattern" || !errors.As(list[0], &syntaxErr) {
+		t.Errorf("Expected : regexp error for pattern, Got : %v", list[0])
+	}
+	// Without a converter for strings, the set is decoded as usual
+	var mismatchErr *ParserTypeMismatchErr
+	if !errors.As(list[1], &mismatchErr) || mismatchErr.Path != "roles" || mismatchErr.Got != StringValue {
+		t.Errorf("Expected : type mismatch for roles, Got : %v", list[1])
+	}
+	if !errors.As(list[2], &unmarshalErr) || unmarshalErr.Path != "other" || unmarshalErr.Line() != 3 {
+		t.Errorf("Expected : wrong converter result for other, Got : %v", list[2])
+	}
+}
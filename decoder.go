@@ -0,0 +1,103 @@
+package aconf
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// Number is the text of a HOCON number, or of the number of bytes of a size, which is decoded into an interface{}
+// when the UseNumber option is set. It can also be the type of a field, to decode numbers of any size or precision.
+type Number string
+
+// String returns the text of the number
+func (n Number) String() string {
+	return string(n)
+}
+
+// Float64 returns the number as a float64
+func (n Number) Float64() (float64, error) {
+	return strconv.ParseFloat(string(n), 64)
+}
+
+// Int64 returns the number as an int64
+func (n Number) Int64() (int64, error) {
+	return strconv.ParseInt(string(n), 10, 64)
+}
+
+// Decoder reads and decodes a HOCON document from an input stream, in the manner of encoding/json's Decoder.
+//
+// The options are set with its methods before calling Decode:
+//
+//	dec := aconf.NewDecoder(reader).WithEnv(os.LookupEnv)
+//	dec.DisallowUnknownFields()
+//	err := dec.Decode(&config)
+type Decoder struct {
+	reader   io.Reader
+	parser   HoconParser
+	decoded  bool
+	metadata *Metadata
+}
+
+// NewDecoder returns a new decoder which reads from the reader
+func NewDecoder(reader io.Reader) *Decoder {
+	return &Decoder{reader: reader}
+}
+
+// DisallowUnknownFields makes keys which do not match any field of the target struct an error
+func (dec *Decoder) DisallowUnknownFields() {
+	dec.parser.DisallowUnknownKeys = true
+}
+
+// UseNumber decodes numbers into an interface{} as a Number instead of an int64 or a float64
+func (dec *Decoder) UseNumber() {
+	dec.parser.UseNumber = true
+}
+
+// WithIncludeResolver sets the resolver which opens the files named in include statements
+func (dec *Decoder) WithIncludeResolver(resolver IncludeResolver) *Decoder {
+	dec.parser.IncludeResolver = resolver
+	return dec
+}
+
+// WithEnv sets the function which looks up environment variables for substitutions such as ${HOME} whose path is
+// not set in the document, usually os.LookupEnv
+func (dec *Decoder) WithEnv(lookupEnv func(key string) (string, bool)) *Decoder {
+	dec.parser.LookupEnv = lookupEnv
+	return dec
+}
+
+// RegisterConverter registers a converter for values of type from into targets of type to, as with
+// HoconParser.RegisterConverter
+func (dec *Decoder) RegisterConverter(from ValueType, to reflect.Type, fn ConverterFunc) {
+	dec.parser.RegisterConverter(from, to, fn)
+}
+
+// Decode reads the whole HOCON document from its input and decodes it into v, which must be a non-nil pointer.
+//
+// The input holds a single document, so Decode returns io.EOF if it is called again.
+func (dec *Decoder) Decode(v interface{}) error {
+	if dec.decoded {
+		return io.EOF
+	}
+	dec.decoded = true
+	dec.metadata = &Metadata{}
+	return dec.parser.parse(dec.reader, v, dec.metadata)
+}
+
+// Metadata returns which keys were decoded by Decode, which were unused, which fields were left unset and where
+// each value was set. It is nil before Decode is called
+func (dec *Decoder) Metadata() *Metadata {
+	return dec.metadata
+}
+
+// More reports whether there is a document left to decode, i.e. whether Decode has not been called yet
+func (dec *Decoder) More() bool {
+	return !dec.decoded
+}
+
+// Unmarshal decodes the HOCON document in data into v, which must be a non-nil pointer
+func Unmarshal(data []byte, v interface{}) error {
+	return NewDecoder(bytes.NewReader(data)).Decode(v)
+}
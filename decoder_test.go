@@ -0,0 +1,89 @@
+package aconf
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecoder(t *testing.T) {
+	type TargetStruct struct {
+		Name   string                 `hocon:"name"`
+		Home   string                 `hocon:"home"`
+		Port   int                    `hocon:"port"`
+		Debug  bool                   `hocon:"debug"`
+		Total  Number                 `hocon:"total"`
+		Extras map[string]interface{} `hocon:"extras"`
+	}
+	contents := `
+	include "common.conf"
+	home = ${HOME}
+	port = ${ PORT }
+	debug = ${?DEBUG}
+	total = 12345678901234567890
+	extras { limit = 10, ratio = 0.5, size = 1KB }
+	`
+	env := map[string]string{"HOME": "/home/app", "PORT": "8080"}
+	dec := NewDecoder(strings.NewReader(contents)).
+		WithEnv(func(key string) (string, bool) {
+			value, ok := env[key]
+			return value, ok
+		}).
+		WithIncludeResolver(func(name string) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("name = app")), nil
+		})
+	dec.UseNumber()
+	dec.DisallowUnknownFields()
+
+	if !dec.More() {
+		t.Errorf("Expected : More before Decode")
+	}
+	target := &TargetStruct{}
+	if err := dec.Decode(target); err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+	want := &TargetStruct{"app", "/home/app", 8080, false, "12345678901234567890",
+		map[string]interface{}{"limit": Number("10"), "ratio": Number("0.5"), "size": Number("1024")}}
+	if !reflect.DeepEqual(target, want) {
+		t.Errorf("Got: %+v, Want : %+v", target, want)
+	}
+	if dec.More() || dec.Decode(target) != io.EOF {
+		t.Errorf("Expected : %v after the document is decoded", io.EOF)
+	}
+}
+
+func TestDecoderErrors(t *testing.T) {
+	type TargetStruct struct {
+		Home string `hocon:"home"`
+	}
+	dec := NewDecoder(strings.NewReader("home = ${HOME}\nuser = x"))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&TargetStruct{})
+	var substitutionErr *LexUnresolvedSubstitutionErr
+	var unknownErr *ParserUnknownKeyErr
+	if !errors.As(err, &substitutionErr) || substitutionErr.Expression != "${HOME}" || !errors.As(err, &unknownErr) {
+		t.Errorf("Expected : unresolved ${HOME} and unknown key user, Got : %v", err)
+	}
+
+	strict := &HoconParser{StrictTypes: true}
+	var n struct{ N Number }
+	if err := strict.Parse(strings.NewReader(`N = "10"`), &n); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected : %v, Got : %v", ErrTypeMismatch, err)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	var target map[string]interface{}
+	if err := Unmarshal([]byte("a { b = 1 }"), &target); err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+	want := map[string]interface{}{"a": map[string]interface{}{"b": int64(1)}}
+	if !reflect.DeepEqual(target, want) {
+		t.Errorf("Got: %v, Want : %v", target, want)
+	}
+	if err := Unmarshal([]byte("a = 1"), target); err == nil {
+		t.Errorf("Expected : %v, Got : %v", &ParserInvalidTargetErr{Got: "map", Want: "ptr"}, err)
+	}
+}
@@ -1,6 +1,7 @@
 /*
 Package hocon parses a HOCON file, validates it's syntax and creates a map of property keys and their values.
-The lexer emits tokens on a channel for clients (parsers) of the lexer to consume.
+The lexer emits tokens one at a time from its Next method for clients (parsers) of the lexer to consume,
+reading the input incrementally rather than all at once.
 The tokens emitted by the lexer consist of a struct :
 type HoconToken struct {
 	tokenType HoconTokenType
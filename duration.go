@@ -0,0 +1,79 @@
+package aconf
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationUnits maps the units of the HOCON duration format to their length
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond, "nano": time.Nanosecond, "nanos": time.Nanosecond, "nanosecond": time.Nanosecond, "nanoseconds": time.Nanosecond,
+	"us": time.Microsecond, "micro": time.Microsecond, "micros": time.Microsecond, "microsecond": time.Microsecond, "microseconds": time.Microsecond,
+	"ms": time.Millisecond, "milli": time.Millisecond, "millis": time.Millisecond, "millisecond": time.Millisecond, "milliseconds": time.Millisecond,
+	"s": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+// ParseDuration parses a value in the HOCON duration format, i.e. a number with an optional decimal fraction
+// followed by an optional unit, such as 10 seconds, 1.5 hours or 0.5s. Bare numbers are taken to be milliseconds.
+//
+// Strings accepted by time.ParseDuration with several units, such as 1h30m, are accepted as well.
+// A value with a unit which is not a duration unit results in an *ErrInvalidDurationUnit.
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	body := strings.TrimPrefix(s, "-")
+	i := strings.IndexFunc(body, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if i < 0 {
+		i = len(body)
+	}
+	magnitude, unit := body[:i], strings.TrimSpace(body[i:])
+	if magnitude == "" || magnitude == "." || strings.Count(magnitude, ".") > 1 {
+		return 0, &ErrInvalidDuration{Value: s}
+	}
+	if len(body) < len(s) {
+		val, _ := strconv.ParseFloat(magnitude, 64)
+		return 0, &ErrLexerInvalidDuration{Value: -val}
+	}
+	if unit == "" {
+		unit = "ms"
+	}
+	unitScale, ok := durationUnits[unit]
+	if !ok {
+		if d, err := time.ParseDuration(s); err == nil {
+			return d, nil
+		}
+		return 0, &ErrInvalidDurationUnit{Value: s, Unit: unit}
+	}
+
+	// Keep the whole part exact, so that large values do not lose precision to floating point
+	whole, fraction := magnitude, ""
+	if j := strings.IndexByte(magnitude, '.'); j >= 0 {
+		whole, fraction = magnitude[:j], magnitude[j+1:]
+	}
+	var d time.Duration
+	if whole != "" {
+		n, err := strconv.ParseInt(whole, 10, 64)
+		if err != nil || n > math.MaxInt64/int64(unitScale) {
+			return 0, &ErrInvalidDuration{Value: s}
+		}
+		d = time.Duration(n) * unitScale
+	}
+	if fraction != "" {
+		f, err := strconv.ParseFloat("0."+fraction, 64)
+		if err != nil {
+			return 0, &ErrInvalidDuration{Value: s}
+		}
+		// The fraction is at most a whole unit, but adding it may still overflow
+		frac := time.Duration(math.Round(f * float64(unitScale)))
+		if frac > math.MaxInt64-d {
+			return 0, &ErrInvalidDuration{Value: s}
+		}
+		d += frac
+	}
+	return d, nil
+}
@@ -0,0 +1,47 @@
+package aconf
+
+import (
+	"testing"
+	"time"
+)
+
+var parseDurationTests = []struct {
+	value    string
+	duration time.Duration
+}{
+	{"10", 10 * time.Millisecond},
+	{"10 seconds", 10 * time.Second},
+	{"10seconds", 10 * time.Second},
+	{"1.5 hours", 90 * time.Minute},
+	{"0.5s", 500 * time.Millisecond},
+	{".5 ms", 500 * time.Microsecond},
+	{"1.5", 1500 * time.Microsecond},
+	{"2 w", 14 * 24 * time.Hour},
+	{"1h30m", 90 * time.Minute},
+	{"1.5h10s", 90*time.Minute + 10*time.Second},
+	{"3µs", 3 * time.Microsecond},
+	{"9223372036.8s", 9223372036800000000},
+}
+
+func TestParseDuration(t *testing.T) {
+	for _, test := range parseDurationTests {
+		d, err := ParseDuration(test.value)
+		if err != nil || d != test.duration {
+			t.Errorf("input: %v, Got: %v, Want: %v, err = %v", test.value, d, test.duration, err)
+		}
+	}
+}
+
+func TestInvalidDurations(t *testing.T) {
+	for _, value := range []string{"", "seconds", "1.2.3s", "99999999999999999999 days", "9223372036.9s", "9223372036854775807.5ns"} {
+		if _, err := ParseDuration(value); err == nil {
+			t.Errorf("input: %v, Expected : %v, Got : nil", value, &ErrInvalidDuration{Value: value})
+		}
+	}
+	if _, err := ParseDuration("10 fortnights"); err == nil || err.(*ErrInvalidDurationUnit).Unit != "fortnights" {
+		t.Errorf("Expected : %v, Got : %v", &ErrInvalidDurationUnit{Value: "10 fortnights", Unit: "fortnights"}, err)
+	}
+	if _, err := ParseDuration("-5 seconds"); err == nil || err.(*ErrLexerInvalidDuration).Value != -5 {
+		t.Errorf("Expected : %v, Got : %v", &ErrLexerInvalidDuration{Value: -5}, err)
+	}
+}
@@ -0,0 +1,164 @@
+package aconf
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IncludeResolver opens the file named in an include statement.
+//
+// A relative name is given relative to the directory of the including file, when the name of that file is known
+// from the Name method of its reader, as for an *os.File.
+//
+// An error for which errors.Is(err, os.ErrNotExist) holds means that the file does not exist,
+// in which case the include statement is ignored unless the file is required.
+type IncludeResolver func(name string) (io.ReadCloser, error)
+
+// includeExtensions are tried in turn for an include statement naming a file without an extension.
+// All the files found are merged, with files in HOCON format merged last.
+var includeExtensions = []string{".properties", ".json", ".conf"}
+
+var errIncludeCycle = errors.New("include cycle detected")
+
+// namedReader gives the reader returned by an IncludeResolver the name of the included file
+type namedReader struct {
+	io.Reader
+	name string
+}
+
+func (r *namedReader) Name() string {
+	return r.name
+}
+
+// includePath returns the name of an included file relative to the directory of the including file, as HOCON
+// requires, rather than to the working directory. Absolute names are kept as they are
+func (parser *parseState) includePath(name string) string {
+	if parser.fileName == "" || filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(filepath.Dir(parser.fileName), name)
+}
+
+func openIncludeFile(name string) (io.ReadCloser, error) {
+	return os.Open(name)
+}
+
+// isIncludeStatement reports whether the token, which is the next token to be consumed, is the unquoted key include,
+// followed by a resource name
+func (parser *parseState) isIncludeStatement(token HoconToken) bool {
+	if token.Type != Key || token.Value != "include" {
+		return false
+	}
+	return parser.peekIs(1, Text) || (parser.peekIs(1, Key) && parser.peekIs(2, LeftParen))
+}
+
+// parseInclude consumes the resource name of an include statement and returns the root object of the included file(s).
+//
+// Assumes that the include keyword has already been consumed
+func (parser *parseState) parseInclude(include HoconToken) (*hoconNode, error) {
+	name, required, err := parser.parseIncludeResource(include)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{name}
+	if filepath.Ext(name) == "" {
+		names = names[:0]
+		for _, ext := range includeExtensions {
+			names = append(names, name+ext)
+		}
+	}
+
+	root := newObjectNode(include.LexLocation)
+	found := false
+	for _, n := range names {
+		included, err := parser.includeFile(n)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, &ParserIncludeErr{n, err, include.LexLocation}
+		}
+		found = true
+		root.merge(included)
+	}
+	if required && !found {
+		return nil, &ParserIncludeErr{name, os.ErrNotExist, include.LexLocation}
+	}
+	return root, nil
+}
+
+// parseIncludeResource consumes either a quoted string, or a quoted string surrounded by file() or required()
+func (parser *parseState) parseIncludeResource(include HoconToken) (name string, required bool, err error) {
+	token, ok := parser.peek(0)
+	if !ok {
+		return "", false, &ParserInvalidIncludeErr{include}
+	}
+	switch {
+	case token.Type == Text:
+		parser.advance()
+		return token.Value, false, nil
+	case token.Type == Key && parser.peekIs(1, LeftParen):
+		parser.advance()
+		parser.advance()
+		switch token.Value {
+		case "required":
+			name, _, err = parser.parseIncludeResource(token)
+			required = true
+		case "file":
+			name, required, err = parser.parseIncludeResource(token)
+		default:
+			// url() and classpath() resources are not supported
+			return "", false, &ParserInvalidIncludeErr{token}
+		}
+		if err != nil {
+			return "", false, err
+		}
+		if !parser.peekIs(0, RightParen) {
+			return "", false, &ParserInvalidIncludeErr{token}
+		}
+		parser.advance()
+		return name, required, nil
+	}
+	return "", false, &ParserInvalidIncludeErr{include}
+}
+
+// includeFile opens the named file using the IncludeResolver and builds the tree of its contents.
+// Files with a .properties extension are read as Java properties, all others as HOCON.
+func (parser *parseState) includeFile(name string) (*hoconNode, error) {
+	name = parser.includePath(name)
+	for _, n := range parser.includes {
+		if n == name {
+			return nil, errIncludeCycle
+		}
+	}
+	resolver := parser.IncludeResolver
+	if resolver == nil {
+		resolver = openIncludeFile
+	}
+	reader, err := resolver(name)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	// Locations in the included file are reported using its name, which nested includes are relative to
+	named := &namedReader{reader, name}
+	var root *hoconNode
+	if strings.EqualFold(filepath.Ext(name), ".properties") {
+		root, err = parseProperties(named)
+	} else {
+		included := &parseState{HoconParser: parser.HoconParser, includes: append(parser.includes[:len(parser.includes):len(parser.includes)], name)}
+		root, err = included.parseTree(named)
+		if root == nil && err == nil {
+			root = newObjectNode(LexLocation{fileName: name, lineNumber: 1, columnNumber: 1})
+		}
+	}
+	if root != nil {
+		root.setSource(OriginInclude)
+	}
+	return root, err
+}
@@ -2,27 +2,98 @@ package aconf
 
 import "fmt"
 
+// LexLocation is the position of a token, or of the input causing an error, in the HOCON source
 type LexLocation struct {
+	fileName     string
 	lineNumber   int
 	columnNumber int
+	offset       int
+}
+
+// FileName returns the name of the file, or an empty string if the input was not read from a named file
+func (l LexLocation) FileName() string {
+	return l.fileName
+}
+
+// Line returns the line number, starting at 1. It is 0 if the location is unknown.
+func (l LexLocation) Line() int {
+	return l.lineNumber
+}
+
+// Column returns the column number in runes, starting at 1
+func (l LexLocation) Column() int {
+	return l.columnNumber
+}
+
+// Offset returns the byte offset from the start of the input, starting at 0
+func (l LexLocation) Offset() int {
+	return l.offset
+}
+
+// Location returns the location itself, it is promoted to the tokens and errors embedding a LexLocation
+func (l LexLocation) Location() LexLocation {
+	return l
+}
+
+// setLocation is promoted to the errors embedding a LexLocation, so that the location can be filled in
+// by the caller when the error is created without one
+func (l *LexLocation) setLocation(location LexLocation) {
+	*l = location
+}
+
+// locatable is implemented by the errors embedding a LexLocation
+type locatable interface {
+	Line() int
+	setLocation(location LexLocation)
+}
+
+// withLocation fills in the location of the error, if it embeds a LexLocation which is not known yet
+func withLocation(err error, location LexLocation) error {
+	if e, ok := err.(locatable); ok && e.Line() == 0 {
+		e.setLocation(location)
+	}
+	return err
+}
+
+// errorf formats an error message following the file:line:col: message convention.
+// The file name is left out if unknown, and the position if the line is unknown.
+func (l LexLocation) errorf(format string, args ...interface{}) string {
+	msg := fmt.Sprintf(format, args...)
+	switch {
+	case l.lineNumber == 0 && l.fileName == "":
+		return msg
+	case l.lineNumber == 0:
+		return fmt.Sprintf("%s: %s", l.fileName, msg)
+	case l.fileName == "":
+		return fmt.Sprintf("%d:%d: %s", l.lineNumber, l.columnNumber, msg)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", l.fileName, l.lineNumber, l.columnNumber, msg)
 }
 
 type LexInvalidTokenErr struct {
-	tokenValue string
+	TokenValue string
 	LexLocation
 }
 
 func (e *LexInvalidTokenErr) Error() string {
-	return fmt.Sprintf("lexer: %d:%d : Invalid Token %s", e.lineNumber, e.columnNumber, e.tokenValue)
+	return e.errorf("lexer: Invalid Token %s", e.TokenValue)
+}
+
+func (e *LexInvalidTokenErr) Is(target error) bool {
+	return target == ErrSyntax
 }
 
 type LexScannerErr struct {
-	msg string
+	Msg string
 	LexLocation
 }
 
 func (e *LexScannerErr) Error() string {
-	return fmt.Sprintf("lexer: %d:%d : %s", e.lineNumber, e.columnNumber, e.msg)
+	return e.errorf("lexer: %s", e.Msg)
+}
+
+func (e *LexScannerErr) Is(target error) bool {
+	return target == ErrSyntax
 }
 
 type ErrReaderNil struct{}
@@ -43,21 +114,75 @@ type ErrLexerInvalidToken struct {
 }
 
 func (e *ErrLexerInvalidToken) Error() string {
-	return fmt.Sprintf("lexer: %d:%d Invalid Token %v", e.lineNumber, e.columnNumber, e.TokenText)
+	return e.errorf("lexer: Invalid Token %v", e.TokenText)
+}
+
+func (e *ErrLexerInvalidToken) Is(target error) bool {
+	return target == ErrSyntax
 }
 
 type ErrLexerInvalidDuration struct {
-	val float64
+	Value float64
+	LexLocation
 }
 
 func (e *ErrLexerInvalidDuration) Error() string {
-	return fmt.Sprintf("lexer: Invalid Duration : %f. Expecting +ve value", e.val)
+	return e.errorf("lexer: Invalid Duration : %f. Expecting +ve value", e.Value)
+}
+
+func (e *ErrLexerInvalidDuration) Is(target error) bool {
+	return target == ErrSyntax
 }
 
 type ErrLexerInvalidSize struct {
-	val int
+	Value int
+	LexLocation
 }
 
 func (e *ErrLexerInvalidSize) Error() string {
-	return fmt.Sprintf("lexer: Invalid Size : %d. Expecting +ve value", e.val)
+	return e.errorf("lexer: Invalid Size : %d. Expecting +ve value", e.Value)
+}
+
+func (e *ErrLexerInvalidSize) Is(target error) bool {
+	return target == ErrSyntax
+}
+
+type ErrInvalidDuration struct {
+	Value string
+	LexLocation
+}
+
+func (e *ErrInvalidDuration) Error() string {
+	return e.errorf("Invalid Duration : %s", e.Value)
+}
+
+func (e *ErrInvalidDuration) Is(target error) bool {
+	return target == ErrSyntax
+}
+
+type ErrInvalidDurationUnit struct {
+	Value string
+	Unit  string
+	LexLocation
+}
+
+func (e *ErrInvalidDurationUnit) Error() string {
+	return e.errorf("Invalid Duration : %s. Unknown unit %q", e.Value, e.Unit)
+}
+
+func (e *ErrInvalidDurationUnit) Is(target error) bool {
+	return target == ErrSyntax
+}
+
+type LexUnresolvedSubstitutionErr struct {
+	Expression string
+	LexLocation
+}
+
+func (e *LexUnresolvedSubstitutionErr) Error() string {
+	return e.errorf("lexer: substitution %s cannot be resolved", e.Expression)
+}
+
+func (e *LexUnresolvedSubstitutionErr) Is(target error) bool {
+	return target == ErrUnresolvedSubstitution
 }
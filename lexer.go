@@ -1,32 +1,51 @@
 package aconf
 
 import (
-	"bytes"
+	"bufio"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"regexp"
 	"strconv"
 	"strings"
-	"text/scanner"
-	"time"
 	"unicode"
+	"unicode/utf8"
 )
 
 type HoconToken struct {
 	Type  HoconTokenType
 	Value string
+	// Raw is the text of an unquoted value as it was written, which is kept because the Value of a duration or
+	// a size is its number of nanoseconds or bytes
+	Raw string
 	LexLocation
 }
 
+// text returns the value as it was written
+func (token HoconToken) text() string {
+	if token.Raw != "" {
+		return token.Raw
+	}
+	return token.Value
+}
+
 type HoconTokenType uint8
 
-var forbiddenCharactersRegEx *regexp.Regexp
+var (
+	durationRegEx = regexp.MustCompile(`^(\d+(?:\.\d*)?|\.\d+)\s*(s|second|seconds|ms|milli|millis|millisecond|milliseconds|ns|nano|nanos|nanosecond|nanoseconds|us|micro|micros|microsecond|microseconds|m|minute|minutes|h|hour|hours|d|day|days|w|week|weeks)$`)
+	// Durations in the format accepted by time.ParseDuration, e.g. 1h30m
+	goDurationRegEx = regexp.MustCompile(`^(?:(?:\d+(?:\.\d*)?|\.\d+)(?:ns|us|µs|ms|s|m|h))+$`)
+	// Days and weeks are matched as durations, so only the units which are unique to periods are matched here
+	periodRegEx = regexp.MustCompile(`^(\d+)\s*(mo|month|months|y|year|years)$`)
+	sizeRegEx   = regexp.MustCompile(`^(\d+)\s*(B|b|byte|bytes|kb|kB|Kb|KB|kilobyte|kilobytes|mb|mB|Mb|MB|megabyte|megabytes|gb|Gb|GB|gB|gigabyte|gigabytes)`)
+)
 
 const NL = 0x0A
 const HASH = 0x23
 const HoconWS = 0x20
 
+// eof is returned by the lexer's rune reading functions at the end of the input
+const eof = -1
+
 const (
 	Integer HoconTokenType = iota
 	Float
@@ -34,6 +53,7 @@ const (
 	Identifier
 	Duration
 	Size
+	TimePeriod
 	Key
 	Text
 	LeftBrace
@@ -47,73 +67,63 @@ const (
 	Comma
 	NewLine
 	Other
+	Substitution
 )
 
 type HoconLexer struct {
-	scanner       scanner.Scanner
+	reader *bufio.Reader
+	// fileName is the name of the file being read, if any
+	fileName string
+	// line, column and offset are the position of the next rune to be read
+	line          int
+	column        int
+	offset        int
 	previousToken HoconToken
 	currentToken  HoconToken
-	err           error
+	// err is the error found while scanning the current token, after which lexing resumes at the next line
+	err error
+	// invalid is the token returned by Next along with err
+	invalid HoconToken
+	// readErr is the error returned by the reader, after which lexing stops
+	readErr error
+	// pending holds the tokens scanned but not yet returned by Next
+	pending []HoconToken
+	eof     bool
+	// brackets holds the currently open braces and brackets, so that keys are not looked for inside arrays
+	brackets []rune
 }
 
 // NewLexer instantiates a new HoconLexer using the provided io.Reader
-// Returns an error in case of
+// Returns an error in case of a nil io.Reader
 func NewLexer(reader io.Reader) (*HoconLexer, error) {
-	var lexer HoconLexer
-	var err error
 	if reader == nil {
 		return nil, &ErrReaderNil{}
 	}
-	lexer = HoconLexer{}
-	lexer.scanner = scanner.Scanner{}
-	fileContents, err := ioutil.ReadAll(reader)
-	if err != nil {
-		return nil, err
-	}
-	replacer := strings.NewReplacer(`"""`, "`")
-	s := replacer.Replace(string(fileContents))
-	r := strings.NewReader(s)
-	lexer.scanner.Init(r)
-
-	isIdentRune := func(ch rune, i int) bool {
-		//return ch == '@' || ch == '_' || unicode.IsLetter(ch) || unicode.IsDigit(ch) && i > 0
-		// If an identifier contains forbidden characters, then return false
-
-		return (ch == '-' || ch == '_' || ch == '.' || unicode.IsLetter(ch) || unicode.IsDigit(ch) && i > 0)
+	// The start of the input is treated like the start of a new line
+	lexer := &HoconLexer{
+		reader:        bufio.NewReader(reader),
+		fileName:      readerName(reader),
+		line:          1,
+		column:        1,
+		previousToken: HoconToken{Type: NewLine, Value: "NewLine"},
 	}
-	lexer.scanner.IsIdentRune = isIdentRune
-
-	errorHandler := func(s *scanner.Scanner, msg string) {
-		lexer.err = &LexScannerErr{msg, LexLocation{lexer.scanner.Line, lexer.scanner.Column}}
-	}
-	lexer.scanner.Error = errorHandler
-	return &lexer, err
+	return lexer, nil
 }
 
 var tokenTypeMap map[rune]HoconTokenType
 
 func init() {
-	var err error
 	tokenTypeMap = make(map[rune]HoconTokenType)
 
 	tokenTypeMap['{'] = LeftBrace
 	tokenTypeMap['}'] = RightBrace
 	tokenTypeMap['['] = LeftBracket
 	tokenTypeMap[']'] = RightBracket
+	tokenTypeMap['('] = LeftParen
+	tokenTypeMap[')'] = RightParen
 	tokenTypeMap[':'] = Colon
 	tokenTypeMap['='] = Equals
 	tokenTypeMap[','] = Comma
-	tokenTypeMap[scanner.Ident] = Identifier
-	tokenTypeMap[scanner.Int] = Integer
-	tokenTypeMap[scanner.Float] = Float
-	tokenTypeMap[scanner.String] = Text
-	tokenTypeMap[scanner.RawString] = Text
-
-	forbiddenCharactersRegEx, err = regexp.Compile(`(\$|\"|\{|\}|\[|\]|:|=|,|\+|#|` + "`" + `|\^|\?|!|@|\*|&|\/\/)`)
-	if err != nil {
-		panic("Could not parse forbidden characters Regex")
-	}
-
 }
 
 /*
@@ -127,157 +137,496 @@ Values are unquoted strings and quoted strings to the right of an = or :
 A Key/Path expression must always start on a new line
 
 Trailing spaces in Values should be trimmed, unless they are in a quoted string.
+
+Run collects all the tokens returned by Next, see Next to read the tokens one at a time instead.
+Invalid tokens are included as tokens of type Other, and the errors for them are returned together as an ErrorList
+if there is more than one.
 */
 func (lexer *HoconLexer) Run() ([]HoconToken, error) {
 	var tokens []HoconToken
-	//var err error
+	var errs ErrorList
 	if lexer == nil {
 		return nil, &ErrLexerNotInitialized{}
 	}
-	durationRegEx, err := regexp.Compile(`^(\d+)\s*(s|second|seconds|ms|milli|millis|millisecond|milliseconds|ns|nano|nanos|nanosecond|nanoseconds|us|micro|micros|microsecond|microseconds|m|minute|minutes|h|hour|hours|d|day|days|w|week|weeks)$`)
-	if err != nil {
-		return nil, err
+	for {
+		token, err := lexer.Next()
+		if err == io.EOF {
+			return tokens, errs.err()
+		}
+		if err != nil {
+			errs.add(err)
+			if token.Type != Other {
+				return tokens, errs.err()
+			}
+		}
+		tokens = append(tokens, token)
 	}
-	sizeRegEx, err := regexp.Compile(`^(\d+)\s*(B|b|byte|bytes|kb|kB|Kb|KB|kilobyte|kilobytes|mb|mB|Mb|MB|megabyte|megabytes|gb|Gb|GB|gB|gigabyte|gigabytes)`)
-	if err != nil {
-		return nil, err
+}
+
+// Next returns the next token read from the input, reading no more of the input than is needed to do so.
+// It returns io.EOF once all the tokens have been returned.
+//
+// An invalid token is returned as a token of type Other along with the error describing it.
+// The rest of its line is skipped, and the following calls to Next carry on from the next line.
+// Any other error, such as one returned by the reader, is returned with an empty token and ends the input.
+func (lexer *HoconLexer) Next() (HoconToken, error) {
+	if lexer == nil || lexer.reader == nil {
+		return HoconToken{}, &ErrLexerNotInitialized{}
 	}
+	for len(lexer.pending) == 0 && lexer.err == nil && lexer.readErr == nil && !lexer.eof {
+		lexer.scan()
+	}
+	if lexer.err != nil && len(lexer.pending) == 0 {
+		token, err := lexer.invalid, lexer.err
+		lexer.err = nil
+		lexer.skipComment()
+		lexer.previousToken = token
+		if lexer.peek() == NL {
+			lexer.emit(HoconToken{NewLine, "NewLine", "", lexer.location()})
+		}
+		return token, err
+	}
+	if len(lexer.pending) == 0 {
+		if lexer.readErr != nil {
+			return HoconToken{}, lexer.readErr
+		}
+		return HoconToken{}, io.EOF
+	}
+	token := lexer.pending[0]
+	lexer.pending = lexer.pending[1:]
+	return token, nil
+}
 
-	forbiddenCharactersRegEx, err := regexp.Compile(`(\$|\"|\{|\}|\[|\]|:|=|,|\+|#|` + "`" + `|\^|\?|!|@|\*|&|\/\/)`)
-	if err != nil {
-		return nil, err
+// emit queues a token to be returned by Next and remembers it as the previous token
+func (lexer *HoconLexer) emit(token HoconToken) {
+	lexer.pending = append(lexer.pending, token)
+	lexer.previousToken = token
+}
+
+// scan reads the next token from the input and queues the resulting tokens, if any
+func (lexer *HoconLexer) scan() {
+	var tokenValue, raw string
+	var tokenType HoconTokenType
+
+	if lexer.skipWhitespace() {
+		return
+	}
+	r := lexer.peek()
+	location := lexer.location()
+	if lexer.readErr != nil {
+		return
 	}
 
-	for token := lexer.scanner.Scan(); token != scanner.EOF && lexer.err == nil; token = lexer.scanner.Scan() {
+	switch {
+	case r == eof:
+		lexer.eof = true
+		return
+	case r == HASH || (r == '/' && lexer.peekString("//")): // Processing a comment
+		lexer.skipComment()
+		if !lexer.continuesOnNextLine() {
+			lexer.emit(HoconToken{NewLine, "NewLine", "", lexer.location()})
+		}
+		return
+	case r == '{' || r == '}' || r == '[' || r == ']' || r == '(' || r == ')' || r == '=' || r == ':' || r == ',':
+		lexer.next()
+		// Ignore the ':' or '=' if it is going to be followed by an opening Brace or '{'
+		if (r == ':' || r == '=') && lexer.peek() == '{' {
+			return
+		}
+		tokenType = tokenTypeMap[r]
+		tokenValue = string(r)
+		switch r {
+		case '{', '[':
+			lexer.brackets = append(lexer.brackets, r)
+		case '}', ']':
+			if l := len(lexer.brackets); l > 0 {
+				lexer.brackets = lexer.brackets[:l-1]
+			}
+		}
+	case (r == '"' && lexer.isKeyPosition()) || (isUnquotedRune(r) && !lexer.insideArray() && !lexer.isValuePosition()):
+		// Quoted keys retain their quotes, so that the parser can tell path separators apart from quoted periods
+		tokenType = Key
+		tokenValue = lexer.scanPathExpression()
+	case r == '"':
+		tokenType = Text
+		tokenValue = lexer.scanQuotedString()
+	case isUnquotedRune(r):
+		// Values to the right of an = or : are concatenated till NL or a comment or One of the forbidden characters,
+		// while array elements end at whitespace as well
+		tokenValue = lexer.scanUnquoted(lexer.isValuePosition())
+		raw = tokenValue
+		tokenType, tokenValue, lexer.err = valueTokenType(numberTokenType(tokenValue), tokenValue)
+		lexer.err = withLocation(lexer.err, location)
+	case r == '$' && lexer.peekString("${"):
+		// Substitutions are resolved by the parser, so the token holds the whole ${...} expression
+		var builder strings.Builder
+		for r = lexer.peek(); r != eof && r != NL; r = lexer.peek() {
+			builder.WriteRune(lexer.next())
+			if r == '}' {
+				break
+			}
+		}
+		tokenType, tokenValue = Substitution, builder.String()
+		if !strings.HasSuffix(tokenValue, "}") {
+			lexer.err = &LexUnresolvedSubstitutionErr{tokenValue, location}
+		}
+	default:
+		lexer.next()
+		lexer.err = &ErrLexerInvalidToken{string(r), location}
+	}
+	if lexer.readErr != nil {
+		lexer.err = nil
+		return
+	}
+	if lexer.err != nil {
+		lexer.invalid = HoconToken{Other, tokenValue, "", location}
+		return
+	}
+
+	lexer.emit(HoconToken{tokenType, tokenValue, raw, location})
+}
 
-		var tokenValue string
-		var tokenType HoconTokenType
+// numberTokenType returns Integer or Float for values starting with a number as defined by JSON, and Text otherwise
+func numberTokenType(tokenValue string) HoconTokenType {
+	if _, err := strconv.ParseInt(tokenValue, 10, 64); err == nil {
+		return Integer
+	}
+	digits := strings.TrimPrefix(tokenValue, "-")
+	if digits != "" && digits[0] >= '0' && digits[0] <= '9' {
+		return Float
+	}
+	return Text
+}
 
+// valueTokenType determines the type of an unquoted value, which starts with a token of the given type.
+// Values with duration and size units are converted to nanoseconds and bytes respectively
+func valueTokenType(tokenType HoconTokenType, tokenValue string) (HoconTokenType, string, error) {
+	// Is it a boolean
+	if tokenValue == "true" || tokenValue == "false" {
+		tokenType = Boolean
+	} else if durationRegEx.MatchString(tokenValue) || goDurationRegEx.MatchString(tokenValue) {
+		// check if the value starts with a number & ends in duration units
+		d, err := ParseDuration(tokenValue)
 		if err != nil {
-			return nil, err
+			return tokenType, tokenValue, err
+		}
+		tokenValue = fmt.Sprintf("%d", d)
+		tokenType = Duration
+	} else if periodRegEx.MatchString(tokenValue) {
+		tokenType = TimePeriod
+	} else if capGroups := sizeRegEx.FindAllStringSubmatch(tokenValue, -1); capGroups != nil {
+		v := capGroups[0][1]
+		u := capGroups[0][2]
+		var unitScale int
+		switch u {
+		case "B", "b", "byte", "bytes":
+			unitScale = 1
+		case "kb", "KB", "kB", "Kb", "kilobyte", "kilobytes":
+			unitScale = 1024
+		case "mb", "MB", "mB", "Mb", "megabyte", "megabytes":
+			unitScale = 1024 * 1024
+		case "gb", "GB", "gB", "Gb", "gigabyte", "gigabytes":
+			unitScale = 1024 * 1024 * 1024
 		}
+		if s, err := strconv.Atoi(v); err == nil {
+			if s >= 0 {
+				x := unitScale * s
+				tokenValue = fmt.Sprintf("%d", x)
+			} else {
+				return tokenType, tokenValue, &ErrLexerInvalidSize{Value: s}
+			}
+		}
+		tokenType = Size
+	} else if _, err := strconv.ParseFloat(tokenValue, 64); (tokenType == Integer || tokenType == Float) && err != nil {
+		// A number followed by anything other than units, e.g. 10 apples or 2024-01-02, is a string
+		tokenType = Text
+	}
+	return tokenType, tokenValue, nil
+}
 
-		switch token {
+// insideArray reports whether the innermost open bracket is a '['
+func (lexer *HoconLexer) insideArray() bool {
+	l := len(lexer.brackets)
+	return l > 0 && lexer.brackets[l-1] == '['
+}
 
-		case '#': // Processing a comment
-			for r := lexer.scanner.Peek(); r != NL && r != scanner.EOF; {
-				r = lexer.scanner.Next()
-			}
-			hoconToken := HoconToken{Type: NewLine, Value: "NewLine"}
-			tokens = append(tokens, hoconToken)
-			continue
-		case '{', '}', '[', ']', '=', ':', ',', scanner.Ident, scanner.Float, scanner.Int:
-			tokenValue = lexer.scanner.TokenText()
-			// If an Identifier contains any of the forbidden characters, then error out
-			if capGroups := forbiddenCharactersRegEx.FindAllStringSubmatch(tokenValue, -1); token == scanner.Ident && capGroups != nil {
-				return nil, &LexInvalidTokenErr{tokenValue, LexLocation{lexer.scanner.Line, lexer.scanner.Column}}
-			}
-			// Ignore the ':' or '=' if it is going to be followed by an opening Brace or '{'
-			if (token == ':' || token == '=') && lexer.scanner.Peek() == '{' {
-				continue
+// isValuePosition reports whether the next token is the value of a field, i.e. follows an = or :
+func (lexer *HoconLexer) isValuePosition() bool {
+	return lexer.previousToken.Type == Equals || lexer.previousToken.Type == Colon
+}
+
+// continuesOnNextLine reports whether the previous token must be followed by another one, which may be on a later
+// line: the value after an = or :, or the resource name after the include keyword
+func (lexer *HoconLexer) continuesOnNextLine() bool {
+	previous := lexer.previousToken
+	return lexer.isValuePosition() || (previous.Type == Key && previous.Value == "include")
+}
+
+// isKeyPosition reports whether a quoted string scanned next is a key (or part of one) rather than a value
+func (lexer *HoconLexer) isKeyPosition() bool {
+	if lexer.insideArray() {
+		return false
+	}
+	switch lexer.previousToken.Type {
+	case NewLine, LeftBrace, Comma:
+		return true
+	}
+	return false
+}
+
+// isWhitespace reports whether the rune is whitespace as defined by HOCON, i.e. a Unicode space, line or paragraph
+// separator, one of the ASCII whitespace and separator control characters or the BOM
+func isWhitespace(r rune) bool {
+	switch r {
+	case ' ', '\t', '\n', '\v', '\f', '\r', 0x1C, 0x1D, 0x1E, 0x1F, 0xFEFF:
+		return true
+	}
+	return r > unicode.MaxASCII && unicode.In(r, unicode.Zs, unicode.Zl, unicode.Zp)
+}
+
+// isForbidden reports whether the rune may not appear in an unquoted string
+func isForbidden(r rune) bool {
+	switch r {
+	case '$', '"', '{', '}', '[', ']', ':', '=', ',', '+', '#', '`', '^', '?', '!', '@', '*', '&', '\\':
+		return true
+	}
+	return false
+}
+
+// isUnquotedRune reports whether the rune may appear in an unquoted string
+func isUnquotedRune(r rune) bool {
+	return r != eof && !isForbidden(r) && !isWhitespace(r)
+}
+
+// location returns the position of the next rune to be read
+func (lexer *HoconLexer) location() LexLocation {
+	return LexLocation{lexer.fileName, lexer.line, lexer.column, lexer.offset}
+}
+
+// readerName returns the name of the file read by the reader, for readers such as *os.File which have a Name method
+func readerName(reader io.Reader) string {
+	if named, ok := reader.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+	return ""
+}
+
+// peek returns the next rune without consuming it
+func (lexer *HoconLexer) peek() rune {
+	b, err := lexer.reader.Peek(1)
+	if len(b) == 0 {
+		if err != nil && err != io.EOF && lexer.readErr == nil {
+			lexer.readErr = err
+		}
+		return eof
+	}
+	if b[0] < utf8.RuneSelf {
+		return rune(b[0])
+	}
+	b, _ = lexer.reader.Peek(utf8.UTFMax)
+	r, _ := utf8.DecodeRune(b)
+	return r
+}
+
+// peekString reports whether the input continues with the ASCII string s
+func (lexer *HoconLexer) peekString(s string) bool {
+	b, _ := lexer.reader.Peek(len(s))
+	return string(b) == s
+}
+
+// next consumes the next rune and advances the position
+func (lexer *HoconLexer) next() rune {
+	r, size, err := lexer.reader.ReadRune()
+	if err != nil {
+		if err != io.EOF && lexer.readErr == nil {
+			lexer.readErr = err
+		}
+		return eof
+	}
+	lexer.offset += size
+	if r == NL {
+		lexer.line++
+		lexer.column = 1
+	} else {
+		lexer.column++
+	}
+	return r
+}
+
+// skipWhitespace discards whitespace up to the next token or line break. A line break is consumed and ends the previous
+// token wherever it is, e.g. after trailing whitespace or the \r of a \r\n, which is reported by returning true
+// once a NewLine token has been queued for it. Line breaks after a token which continues on the next line are
+// discarded along with the whitespace
+func (lexer *HoconLexer) skipWhitespace() bool {
+	for r := lexer.peek(); isWhitespace(r); r = lexer.peek() {
+		location := lexer.location()
+		lexer.next()
+		if r == NL && lexer.previousToken.Type != NewLine && !lexer.continuesOnNextLine() {
+			lexer.emit(HoconToken{NewLine, "NewLine", "", location})
+			return true
+		}
+	}
+	return false
+}
+
+// skipComment discards the remainder of a # or // comment, leaving the terminating newline to be scanned
+func (lexer *HoconLexer) skipComment() {
+	for r := lexer.peek(); r != NL && r != eof; r = lexer.peek() {
+		lexer.next()
+	}
+}
+
+// scanUnquoted reads an unquoted string. Unless concatenate is set, the string ends at the first whitespace.
+// Surrounding whitespace is trimmed.
+func (lexer *HoconLexer) scanUnquoted(concatenate bool) string {
+	var builder strings.Builder
+	for r := lexer.peek(); r != eof && r != NL && !isForbidden(r) && !(r == '/' && lexer.peekString("//")); r = lexer.peek() {
+		if !concatenate && isWhitespace(r) {
+			break
+		}
+		builder.WriteRune(lexer.next())
+	}
+	return strings.TrimFunc(builder.String(), isWhitespace)
+}
+
+// scanPathExpression reads a key made up of unquoted and quoted strings, such as foo.bar."hello.world".
+// Quoted strings are kept as they are, including their quotes and escapes.
+func (lexer *HoconLexer) scanPathExpression() string {
+	var builder strings.Builder
+	for r := lexer.peek(); lexer.err == nil; r = lexer.peek() {
+		if r == '"' {
+			builder.WriteString(lexer.scanQuoted())
+		} else if isUnquotedRune(r) && r != '(' && r != ')' && !(r == '/' && lexer.peekString("//")) {
+			builder.WriteRune(lexer.next())
+		} else {
+			break
+		}
+	}
+	return builder.String()
+}
+
+// scanQuotedString reads a quoted or a multi-line string, returning its value
+func (lexer *HoconLexer) scanQuotedString() string {
+	if lexer.peekString(`"""`) {
+		lexer.next()
+		lexer.next()
+		lexer.next()
+		return lexer.scanMultilineString()
+	}
+	location := lexer.location()
+	quoted := lexer.scanQuoted()
+	if lexer.err != nil {
+		return ""
+	}
+	value, err := unquote(quoted)
+	if err != nil {
+		lexer.err = &LexScannerErr{"invalid escape sequence in string literal", location}
+	}
+	return value
+}
+
+// scanQuoted reads a quoted string, returning it as it appears in the input, i.e. including its quotes and escapes
+func (lexer *HoconLexer) scanQuoted() string {
+	location := lexer.location()
+	var builder strings.Builder
+	builder.WriteRune(lexer.next())
+	escaped := false
+	for {
+		// The newline ending an unterminated string is left to be scanned, so that lexing can resume at the next line
+		r := lexer.peek()
+		if r == eof || r == NL {
+			if lexer.err == nil {
+				lexer.err = &LexScannerErr{"literal not terminated", location}
 			}
-			tokenType = tokenTypeMap[token]
-			numTokens := len(tokens)
-
-			if token == scanner.Ident || token == scanner.Float || token == scanner.Int {
-
-				if numTokens > 0 && (tokens[numTokens-1].Type == Equals || tokens[numTokens-1].Type == Colon) {
-					var buffer = bytes.NewBuffer([]byte(tokenValue))
-					if tokenType != Integer && tokenType != Float {
-						tokenType = Text
-					}
-
-					// Keep concatenating values till NL or HASH or One of the forbidden characters is encountered
-					for r := lexer.scanner.Peek(); r != NL && r != HASH && r != scanner.EOF && forbiddenCharactersRegEx.FindAllStringSubmatch(string(r), -1) == nil; r = lexer.scanner.Peek() {
-						r = lexer.scanner.Next()
-						buffer.WriteString(string(r))
-					}
-					tokenValue = strings.TrimSpace(buffer.String())
-					// In case of numbers, if the tail of the concatenated string does not match any units, then just strip out the tail and put it in the
-
-					// Is it a boolean
-					if strings.HasPrefix(tokenValue, "true") || strings.HasPrefix(tokenValue, "false") {
-						tokenType = Boolean
-					} else if capGroups := durationRegEx.FindAllStringSubmatch(tokenValue, -1); capGroups != nil {
-						// check if the value starts with a number & ends in duration/size units
-						v := capGroups[0][1]
-						u := capGroups[0][2]
-						var unitScale time.Duration
-						switch u {
-						case "s", "second", "seconds":
-							unitScale = time.Second
-						case "ms", "milli", "millis", "millisecond", "milliseconds":
-							unitScale = time.Millisecond
-						case "us", "micro", "micros", "microsecond", "microseconds":
-							unitScale = time.Microsecond
-						case "ns", "nano", "nanos", "nanosecond", "nanoseconds":
-							unitScale = time.Nanosecond
-						case "m", "minute", "minutes":
-							unitScale = time.Minute
-						case "h", "hour", "hours":
-							unitScale = time.Hour
-						case "d", "day", "days":
-							unitScale = 24 * time.Hour
-						case "w", "week", "weeks":
-							unitScale = 24 * 7 * time.Hour
-						}
-
-						if d, err := strconv.ParseFloat(v, 64); err == nil {
-							if d >= 0 {
-								x := unitScale * time.Duration(d)
-								tokenValue = fmt.Sprintf("%d", x)
-							} else {
-								return nil, &ErrLexerInvalidDuration{d}
-							}
-						}
-						tokenType = Duration
-					} else if capGroups := sizeRegEx.FindAllStringSubmatch(tokenValue, -1); capGroups != nil {
-						v := capGroups[0][1]
-						u := capGroups[0][2]
-						var unitScale int
-						switch u {
-						case "B", "b", "byte", "bytes":
-							unitScale = 1
-						case "kb", "KB", "kB", "Kb", "kilobyte", "kilobytes":
-							unitScale = 1024
-						case "mb", "MB", "mB", "Mb", "megabyte", "megabytes":
-							unitScale = 1024 * 1024
-						case "gb", "GB", "gB", "Gb", "gigabyte", "gigabytes":
-							unitScale = 1024 * 1024 * 1024
-						}
-						if s, err := strconv.Atoi(v); err == nil {
-							if s >= 0 {
-								x := unitScale * s
-								tokenValue = fmt.Sprintf("%d", x)
-							} else {
-								return nil, &ErrLexerInvalidSize{s}
-							}
-						}
-						tokenType = Size
-					}
-				} else if token == scanner.Ident {
-					tokenType = Key
-				}
+			return ""
+		}
+		builder.WriteRune(lexer.next())
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			return builder.String()
+		}
+	}
+}
+
+// scanMultilineString reads the contents of a triple-quoted string, assuming the opening """ has already been consumed.
+//
+// The contents are used unmodified, i.e. no escapes are interpreted and newlines are preserved.
+// Any sequence of three or more quotes ends the string, with the extra quotes being part of the string.
+func (lexer *HoconLexer) scanMultilineString() string {
+	var builder strings.Builder
+	quotes := 0
+	for {
+		r := lexer.peek()
+		if r != '"' && quotes >= 3 {
+			builder.WriteString(strings.Repeat(`"`, quotes-3))
+			return builder.String()
+		}
+		if r == eof {
+			if lexer.err == nil {
+				lexer.err = &LexScannerErr{"multi-line string not terminated", lexer.location()}
 			}
-		case scanner.String, scanner.RawString:
-			tokenType = tokenTypeMap[token]
-			tokenValue, lexer.err = strconv.Unquote(lexer.scanner.TokenText())
-		case scanner.Char:
-			lexer.err = &ErrLexerInvalidToken{lexer.scanner.TokenText(), LexLocation{lexer.scanner.Line, lexer.scanner.Column}}
-			continue
-		default:
-			lexer.err = &ErrLexerInvalidToken{lexer.scanner.TokenText(), LexLocation{lexer.scanner.Line, lexer.scanner.Column}}
+			return ""
+		}
+		lexer.next()
+		if r == '"' {
+			quotes++
 			continue
 		}
+		builder.WriteString(strings.Repeat(`"`, quotes))
+		quotes = 0
+		builder.WriteRune(r)
+	}
+}
 
-		hoconToken := HoconToken{tokenType, tokenValue, LexLocation{lexer.scanner.Line, lexer.scanner.Column}}
-		tokens = append(tokens, hoconToken)
-
-		if lexer.scanner.Peek() == NL {
-			hoconToken := HoconToken{Type: NewLine, Value: "NewLine"}
-			tokens = append(tokens, hoconToken)
+// unquote returns the value of a quoted string, interpreting the escapes defined by JSON
+func unquote(quoted string) (string, error) {
+	if len(quoted) < 2 || quoted[0] != '"' || quoted[len(quoted)-1] != '"' {
+		return "", strconv.ErrSyntax
+	}
+	s := quoted[1 : len(quoted)-1]
+	if strings.IndexByte(s, '\\') < 0 {
+		return s, nil
+	}
+	var builder strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			builder.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i == len(s) {
+			return "", strconv.ErrSyntax
+		}
+		switch s[i] {
+		case '"', '\\', '/':
+			builder.WriteByte(s[i])
+		case 'b':
+			builder.WriteByte('\b')
+		case 'f':
+			builder.WriteByte('\f')
+		case 'n':
+			builder.WriteByte('\n')
+		case 'r':
+			builder.WriteByte('\r')
+		case 't':
+			builder.WriteByte('\t')
+		case 'u':
+			if i+5 > len(s) {
+				return "", strconv.ErrSyntax
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 32)
+			if err != nil {
+				return "", strconv.ErrSyntax
+			}
+			builder.WriteRune(rune(r))
+			i += 4
+		default:
+			return "", strconv.ErrSyntax
 		}
-
 	}
-	return tokens, lexer.err
+	return builder.String(), nil
 }
@@ -1,8 +1,11 @@
 package aconf
 
 import (
+	"bytes"
+	"io"
 	"strings"
 	"testing"
+	"time"
 )
 
 const (
@@ -17,10 +20,15 @@ const (
 	tokensWithCommentsOnSeparateLines = `
 	# First Line
 	x = 10`
-	tokensWithCommentsAtEndOfValue = `name = axlrate-imdg # This is the grid name`
-	tokenWithHyphenatedKey         = `grid-name = axlrate-imdg`
-	unterminatedLiteralTokens      = `name = "axlrate-`
-	unrecognizedTokens             = `
+	tokensWithCommentsAtEndOfValue         = `name = axlrate-imdg # This is the grid name`
+	tokensWithSlashCommentsOnSeparateLines = `
+	// First Line
+	x = 10`
+	tokensWithSlashCommentsAtEndOfValue = `name = axlrate-imdg // This is the grid name`
+	tokensWithSingleSlashInValue        = `path = a/b // the path`
+	tokenWithHyphenatedKey              = `grid-name = axlrate-imdg`
+	unterminatedLiteralTokens           = `name = "axlrate-`
+	unrecognizedTokens                  = `
 	{
 		*
 		name = "axlrate"
@@ -34,15 +42,24 @@ line1
 line2
 """
 	`
+	multilineStringWithBackticks   = "x = \"\"\"run `make` first\"\"\""
+	multilineStringWithExtraQuotes = `x = """foo""""`
+	multilineStringWithEscapes     = `x = """C:\temp\new"""`
+	quotedStringWithTripleQuotes   = `x = "a\"\"\"b"`
+	quotedKeyTokens                = `"akka.actor" = 10`
+	quotedKeyInPathTokens          = `foo.bar."hello.world" = 10`
+	quotedStringsInArrayTokens     = `x = ["a", "b"]`
 )
 
 var testInvalidTokens = []struct {
 	fileContents string
 	err          error
 }{
-	{tokensWithForbiddenCharacters, &LexInvalidTokenErr{"", LexLocation{1, 8}}},
-	{unterminatedLiteralTokens, &LexScannerErr{"", LexLocation{1, 8}}},
-	{unrecognizedTokens, &LexInvalidTokenErr{"", LexLocation{3, 3}}},
+	{tokensWithForbiddenCharacters, &LexInvalidTokenErr{"", LexLocation{lineNumber: 1, columnNumber: 8}}},
+	{unterminatedLiteralTokens, &LexScannerErr{"", LexLocation{lineNumber: 1, columnNumber: 8}}},
+	{unrecognizedTokens, &LexInvalidTokenErr{"", LexLocation{lineNumber: 3, columnNumber: 3}}},
+	{`x = """never closed`, &LexScannerErr{"", LexLocation{lineNumber: 1, columnNumber: 20}}},
+	{`x = "bad \q escape"`, &LexScannerErr{"", LexLocation{lineNumber: 1, columnNumber: 5}}},
 }
 
 var testValidTokens = []struct {
@@ -59,7 +76,35 @@ var testValidTokens = []struct {
 	{tokenWithSizeUnits, []int{0, 2}, "size", "5368709120", Size},
 	{tokensWithCommentsAtEndOfValue, []int{0, 2}, "name", "axlrate-imdg", Text},
 	{tokensWithUnquotedValues, []int{0, 2}, "name", "axlrate imdg", Text},
+	{tokensWithSlashCommentsOnSeparateLines, []int{1, 3}, "x", "10", Integer},
+	{tokensWithSlashCommentsAtEndOfValue, []int{0, 2}, "name", "axlrate-imdg", Text},
+	{tokensWithSingleSlashInValue, []int{0, 2}, "path", "a/b", Text},
 	{multilineStringTokens, []int{0, 2}, "x", "\nline1\n\"quoted-and-embedded-line\"\nline2\n", Text},
+	{multilineStringWithBackticks, []int{0, 2}, "x", "run `make` first", Text},
+	{multilineStringWithExtraQuotes, []int{0, 2}, "x", `foo"`, Text},
+	{multilineStringWithEscapes, []int{0, 2}, "x", `C:\temp\new`, Text},
+	{quotedStringWithTripleQuotes, []int{0, 2}, "x", `a"""b`, Text},
+	{`t = 1.5 hours`, []int{0, 2}, "t", "5400000000000", Duration},
+	{`t = 0.5s`, []int{0, 2}, "t", "500000000", Duration},
+	{`t = 1h30m`, []int{0, 2}, "t", "5400000000000", Duration},
+	{`t = 10 fortnights`, []int{0, 2}, "t", "10 fortnights", Text},
+	{`d = 2024-01-02`, []int{0, 2}, "d", "2024-01-02", Text},
+	{`p = 2 months`, []int{0, 2}, "p", "2 months", TimePeriod},
+	{`p = 1y`, []int{0, 2}, "p", "1y", TimePeriod},
+	{quotedKeyTokens, []int{0, 2}, `"akka.actor"`, "10", Integer},
+	{quotedKeyInPathTokens, []int{0, 2}, `foo.bar."hello.world"`, "10", Integer},
+	{quotedStringsInArrayTokens, []int{0, 5}, "x", "b", Text},
+	{`x = "tab\there \u00e9\/"`, []int{0, 2}, "x", "tab\there é/", Text},
+	{"x\u00a0=\u2003hello\ufeff", []int{0, 2}, "x", "hello", Text},
+	{`name = it's`, []int{0, 2}, "name", "it's", Text},
+	{`n = -10`, []int{0, 2}, "n", "-10", Integer},
+	{`b = true`, []int{0, 2}, "b", "true", Boolean},
+	{`b = falsehood`, []int{0, 2}, "b", "falsehood", Text},
+	{`b = true story`, []int{0, 2}, "b", "true story", Text},
+	{"A =\n  x", []int{0, 2}, "A", "x", Text},
+	{"A = # c\n  \"x\"", []int{0, 2}, "A", "x", Text},
+	{"A :\r\n\n  10s", []int{0, 2}, "A", "10000000000", Duration},
+	{"include\n  \"base.conf\"", []int{0, 1}, "include", "base.conf", Text},
 }
 
 func TestValidTokens(t *testing.T) {
@@ -117,3 +162,153 @@ func TestInvalidTokens(t *testing.T) {
 
 	}
 }
+
+func TestTokenLocationAfterMultilineString(t *testing.T) {
+	contents := "x = \"\"\"a\nb\nc\"\"\"\ny = 10"
+	l, _ := NewLexer(strings.NewReader(contents))
+	tokens, err := l.Run()
+	if err != nil {
+		t.Fatalf("test failed with non-nil error : Expected : Nil, Got : %v", err)
+	}
+	for _, token := range tokens {
+		if token.Type == Key && token.Value == "y" {
+			if token.lineNumber != 4 || token.columnNumber != 1 {
+				t.Errorf("Mismatched Location -> Got: %d:%d, Want: 4:1", token.lineNumber, token.columnNumber)
+			}
+			return
+		}
+	}
+	t.Errorf("key y not found in tokens %v", tokens)
+}
+
+func TestTokenPositions(t *testing.T) {
+	contents := "a = 1\n  \u00e9 = \"x\""
+	want := []LexLocation{
+		{"", 1, 1, 0}, {"", 1, 3, 2}, {"", 1, 5, 4}, {"", 1, 6, 5},
+		{"", 2, 3, 8}, {"", 2, 5, 11}, {"", 2, 7, 13},
+	}
+	l, _ := NewLexer(strings.NewReader(contents))
+	tokens, err := l.Run()
+	if err != nil {
+		t.Fatalf("test failed with non-nil error : Expected : Nil, Got : %v", err)
+	}
+	if len(tokens) != len(want) {
+		t.Fatalf("Mismatched Tokens -> Got: %v", tokens)
+	}
+	for i, token := range tokens {
+		if token.LexLocation != want[i] {
+			t.Errorf("Token : %v, Mismatched Location -> Got: %d:%d+%d, Want: %d:%d+%d", token.Value, token.Line(), token.Column(), token.Offset(), want[i].Line(), want[i].Column(), want[i].Offset())
+		}
+	}
+}
+
+func TestLexerCarriesOnAfterInvalidToken(t *testing.T) {
+	l, _ := NewLexer(strings.NewReader("a = @b c\nd = 1\ne = \"x\n"))
+	token, err := l.Next()
+	for err == nil {
+		token, err = l.Next()
+	}
+	if _, ok := err.(*ErrLexerInvalidToken); !ok || token.Type != Other || token.Line() != 1 || token.Column() != 5 {
+		t.Errorf("Expected : %v, Got : %v %v", &ErrLexerInvalidToken{"@", LexLocation{lineNumber: 1, columnNumber: 5}}, token, err)
+	}
+
+	l, _ = NewLexer(strings.NewReader("a = @b c\nd = 1\ne = \"x\n"))
+	tokens, err := l.Run()
+	if list, ok := err.(ErrorList); !ok || len(list) != 2 {
+		t.Fatalf("Expected : 2 errors, Got : %v", err)
+	}
+	found := false
+	for _, token := range tokens {
+		found = found || (token.Type == Key && token.Value == "d")
+	}
+	if !found {
+		t.Errorf("key d not found in tokens %v", tokens)
+	}
+}
+
+func FuzzLexer(f *testing.F) {
+	seedCorpus(f)
+	f.Fuzz(func(t *testing.T, contents []byte) {
+		l, _ := NewLexer(bytes.NewReader(contents))
+		l.Run()
+	})
+}
+
+func TestNextReadsIncrementally(t *testing.T) {
+	reader, writer := io.Pipe()
+	l, _ := NewLexer(reader)
+	go writer.Write([]byte("a = 1\n"))
+
+	// The first line must be lexed without waiting for the rest of the input
+	done := make(chan []HoconToken)
+	go func() {
+		var tokens []HoconToken
+		for i := 0; i < 4; i++ {
+			token, err := l.Next()
+			if err != nil {
+				t.Errorf("test failed with non-nil error : Expected : Nil, Got : %v", err)
+			}
+			tokens = append(tokens, token)
+		}
+		done <- tokens
+	}()
+	select {
+	case tokens := <-done:
+		if tokens[0].Value != "a" || tokens[2].Value != "1" || tokens[3].Type != NewLine {
+			t.Errorf("Mismatched Tokens -> Got: %v", tokens)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Next blocked waiting for more input than needed")
+	}
+
+	go func() {
+		writer.Write([]byte(`b = "two"`))
+		writer.Close()
+	}()
+	var values []string
+	for {
+		token, err := l.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("test failed with non-nil error : Expected : Nil, Got : %v", err)
+		}
+		values = append(values, token.Value)
+	}
+	if strings.Join(values, " ") != "b = two" {
+		t.Errorf("Mismatched Tokens -> Got: %v, Want: %v", values, "b = two")
+	}
+	if _, err := l.Next(); err != io.EOF {
+		t.Errorf("Expected : %v, Got : %v", io.EOF, err)
+	}
+}
+
+// benchmarkContents is a generated config, similar to the large configs the lexer has to deal with
+var benchmarkContents = func() string {
+	var b strings.Builder
+	for i := 0; i < 200; i++ {
+		b.WriteString(`service-` + strings.Repeat("x", i%7) + ` {
+	name = "axlrate-imdg" # the grid name
+	hosts = ["10.0.0.1", "10.0.0.2"]
+	port = 10080
+	ratio = 0.75
+	timeout = 10 seconds
+	enabled = true
+	description = an unquoted string // with a comment
+}
+`)
+	}
+	return b.String()
+}()
+
+func BenchmarkLexer(b *testing.B) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchmarkContents)))
+	for i := 0; i < b.N; i++ {
+		l, _ := NewLexer(strings.NewReader(benchmarkContents))
+		if _, err := l.Run(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package aconf
+
+import (
+	"io"
+	"reflect"
+)
+
+// Metadata describes which keys were decoded by a call to ParseWithMetadata or Decoder.Decode.
+// All the keys are given as path expressions, such as servers[0].host
+type Metadata struct {
+	// Keys are the keys which were decoded, in the order they were decoded, including objects and array elements
+	Keys []string
+	// Unused are the keys which match no field of their struct, and so were not decoded
+	Unused []string
+	// Unset are the struct fields which no key was decoded into, and so keep their zero or default values
+	Unset []string
+	// Origins tells where the value of each of the Keys was set
+	Origins map[string]ConfigOrigin
+}
+
+// addKey records that the node was decoded at the path
+func (m *Metadata) addKey(path string, node *hoconNode) {
+	if m.Origins == nil {
+		m.Origins = map[string]ConfigOrigin{}
+	}
+	m.Keys = append(m.Keys, path)
+	m.Origins[path] = node.origin()
+}
+
+// addUnset records the fields of the struct type which none of the keys of the object node match
+func (m *Metadata) addUnset(node *hoconNode, t reflect.Type, path string) {
+	for _, sf := range reflect.VisibleFields(t) {
+		if sf.Anonymous || !sf.IsExported() {
+			continue
+		}
+		if _, options := hoconTag(sf); containsString(options, "remain") {
+			continue
+		}
+		if key, set := fieldKey(node, sf); !set {
+			m.Unset = append(m.Unset, joinPath(path, key))
+		}
+	}
+}
+
+// ParseWithMetadata decodes the HOCON read from the reader into v the same way as Parse, and also returns which
+// keys were decoded, which were unused, which fields were left unset and where each value was set
+func (parser *HoconParser) ParseWithMetadata(hoconContentReader io.Reader, v interface{}) (*Metadata, error) {
+	metadata := &Metadata{}
+	return metadata, parser.parse(hoconContentReader, v, metadata)
+}
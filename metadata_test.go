@@ -0,0 +1,73 @@
+package aconf
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMetadata(t *testing.T) {
+	type Server struct {
+		Host string `hocon:"host"`
+		Port int    `hocon:"port"`
+	}
+	type TargetStruct struct {
+		Name    string            `hocon:"name"`
+		Home    string            `hocon:"home"`
+		Servers []Server          `hocon:"servers"`
+		Labels  map[string]string `hocon:"labels"`
+		Timeout int               `hocon:"timeout"`
+	}
+	contents := `name = app
+home = ${HOME}
+include "servers.conf"
+labels { team = core }
+legacy = true
+`
+	parser := &HoconParser{
+		LookupEnv: func(key string) (string, bool) { return "/home/app", key == "HOME" },
+		IncludeResolver: func(name string) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("servers = [{ host = a }]")), nil
+		},
+	}
+	metadata, err := parser.ParseWithMetadata(strings.NewReader(contents), &TargetStruct{})
+	if err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+	want := &Metadata{
+		Keys:   []string{"name", "home", "servers", "servers[0]", "servers[0].host", "labels", "labels.team"},
+		Unused: []string{"legacy"},
+		Unset:  []string{"servers[0].port", "timeout"},
+	}
+	if !reflect.DeepEqual(metadata.Keys, want.Keys) || !reflect.DeepEqual(metadata.Unused, want.Unused) || !reflect.DeepEqual(metadata.Unset, want.Unset) {
+		t.Errorf("Got: %v %v %v, Want : %v %v %v", metadata.Keys, metadata.Unused, metadata.Unset, want.Keys, want.Unused, want.Unset)
+	}
+
+	origins := map[string]string{
+		"name":            "1:8",
+		"home":            "2:8 (env)",
+		"servers[0].host": "servers.conf:1:21 (include)",
+		"labels.team":     "4:17",
+	}
+	for key, want := range origins {
+		if got := metadata.Origins[key].String(); got != want {
+			t.Errorf("key: %s, Got: %v, Want : %v", key, got, want)
+		}
+	}
+}
+
+func TestDecoderMetadata(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("A = 1\nb = 2"))
+	if dec.Metadata() != nil {
+		t.Errorf("Expected : no metadata before Decode, Got : %v", dec.Metadata())
+	}
+	var target struct{ A, C int }
+	if err := dec.Decode(&target); err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+	metadata := dec.Metadata()
+	if !reflect.DeepEqual(metadata.Keys, []string{"A"}) || !reflect.DeepEqual(metadata.Unused, []string{"b"}) || !reflect.DeepEqual(metadata.Unset, []string{"C"}) {
+		t.Errorf("Got: %+v, Want : keys [A], unused [b], unset [C]", metadata)
+	}
+}
@@ -0,0 +1,352 @@
+package aconf
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type hoconNodeType uint8
+
+const (
+	objectNode hoconNodeType = iota
+	arrayNode
+	valueNode
+)
+
+// hoconNode is an element of the tree which the parser builds out of the lexer tokens.
+//
+// Objects keep their keys in the order in which they were first seen, arrays keep their elements and
+// values keep the token they were created from.
+type hoconNode struct {
+	nodeType hoconNodeType
+	keys     []string
+	fields   map[string]*hoconNode
+	elements []*hoconNode
+	token    HoconToken
+	// source tells how the node was set, e.g. in an included file
+	source OriginKind
+	LexLocation
+}
+
+func newObjectNode(location LexLocation) *hoconNode {
+	return &hoconNode{nodeType: objectNode, fields: make(map[string]*hoconNode), LexLocation: location}
+}
+
+func newArrayNode(location LexLocation) *hoconNode {
+	return &hoconNode{nodeType: arrayNode, LexLocation: location}
+}
+
+func newValueNode(token HoconToken) *hoconNode {
+	return &hoconNode{nodeType: valueNode, token: token, LexLocation: token.LexLocation}
+}
+
+// set assigns the value to the path below the object node, creating intermediate objects as required.
+//
+// As with duplicate keys, a later object value is merged into an earlier one, while any other value replaces it.
+// A path through an array using a numeric index, such as servers.0.host, overrides that element of the array.
+func (node *hoconNode) set(path []string, value *hoconNode) {
+	key := path[0]
+	existing, ok := node.fields[key]
+	if len(path) == 1 {
+		if ok && existing.nodeType == objectNode && value.nodeType == objectNode {
+			existing.merge(value)
+			return
+		}
+		node.setField(key, value)
+		return
+	}
+	if ok && existing.nodeType == arrayNode {
+		if i, isIndex := arrayIndex(path[1]); isIndex {
+			if i < len(existing.elements) {
+				existing.setElement(i, path[2:], value)
+				return
+			}
+			// Adding an element past the end of the array, so continue with its numerically-indexed object equivalent
+			existing = existing.indexedObject()
+			node.fields[key] = existing
+		}
+	}
+	if !ok || existing.nodeType != objectNode {
+		existing = newObjectNode(value.LexLocation)
+		node.setField(key, existing)
+	}
+	existing.set(path[1:], value)
+}
+
+// setElement assigns the value to the path below the i'th element of the array node
+func (node *hoconNode) setElement(i int, path []string, value *hoconNode) {
+	element := node.elements[i]
+	switch {
+	case len(path) == 0 && element.nodeType == objectNode && value.nodeType == objectNode:
+		element.merge(value)
+	case len(path) == 0:
+		node.elements[i] = value
+	case element.nodeType == arrayNode:
+		if j, isIndex := arrayIndex(path[0]); isIndex && j < len(element.elements) {
+			element.setElement(j, path[1:], value)
+			return
+		}
+		node.elements[i] = element.indexedObject()
+		node.elements[i].set(path, value)
+	case element.nodeType == objectNode:
+		element.set(path, value)
+	default:
+		node.elements[i] = newObjectNode(value.LexLocation)
+		node.elements[i].set(path, value)
+	}
+}
+
+// get returns the node at the path below this one, or nil if there is none.
+// An element of an array is selected by its numeric index, such as servers.0.host
+func (node *hoconNode) get(path []string) *hoconNode {
+	for _, key := range path {
+		switch node.nodeType {
+		case objectNode:
+			node = node.fields[key]
+		case arrayNode:
+			i, isIndex := arrayIndex(key)
+			if !isIndex || i >= len(node.elements) {
+				return nil
+			}
+			node = node.elements[i]
+		default:
+			return nil
+		}
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// clone returns a copy of the tree below the node, which can be changed without changing this one
+func (node *hoconNode) clone() *hoconNode {
+	if node == nil {
+		return nil
+	}
+	c := *node
+	if node.fields != nil {
+		c.keys = append([]string(nil), node.keys...)
+		c.fields = make(map[string]*hoconNode, len(node.fields))
+		for key, field := range node.fields {
+			c.fields[key] = field.clone()
+		}
+	}
+	if node.elements != nil {
+		c.elements = make([]*hoconNode, len(node.elements))
+		for i, element := range node.elements {
+			c.elements[i] = element.clone()
+		}
+	}
+	return &c
+}
+
+// setSource marks the nodes of the tree which were set in the file being parsed as having the given source instead
+func (node *hoconNode) setSource(source OriginKind) {
+	if node.source == OriginFile {
+		node.source = source
+	}
+	for _, key := range node.keys {
+		node.fields[key].setSource(source)
+	}
+	for _, element := range node.elements {
+		element.setSource(source)
+	}
+}
+
+// indexedObject converts the array node to an object with the keys "0", "1", ... for its elements
+func (node *hoconNode) indexedObject() *hoconNode {
+	object := newObjectNode(node.LexLocation)
+	for i, element := range node.elements {
+		object.setField(strconv.Itoa(i), element)
+	}
+	return object
+}
+
+// indexedElements converts an object with numeric keys to array elements, sorted by their index.
+// Keys which are not numeric are ignored and ok is false if the object has no numeric keys at all.
+func (node *hoconNode) indexedElements() (elements []*hoconNode, ok bool) {
+	var indices []int
+	byIndex := make(map[int]*hoconNode)
+	for _, key := range node.keys {
+		if i, isIndex := arrayIndex(key); isIndex {
+			if _, found := byIndex[i]; !found {
+				indices = append(indices, i)
+			}
+			byIndex[i] = node.fields[key]
+		}
+	}
+	sort.Ints(indices)
+	for _, i := range indices {
+		elements = append(elements, byIndex[i])
+	}
+	return elements, len(elements) > 0
+}
+
+// arrayIndex reports whether the key is a non-negative integer, which can be used as an array index
+func arrayIndex(key string) (int, bool) {
+	if key == "" {
+		return 0, false
+	}
+	for _, r := range key {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+	}
+	i, err := strconv.Atoi(key)
+	return i, err == nil
+}
+
+// merge copies the fields of the other object into this one, recursively merging fields which are objects in both
+func (node *hoconNode) merge(other *hoconNode) {
+	for _, key := range other.keys {
+		node.set([]string{key}, other.fields[key])
+	}
+}
+
+func (node *hoconNode) setField(key string, value *hoconNode) {
+	if _, ok := node.fields[key]; !ok {
+		node.keys = append(node.keys, key)
+	}
+	node.fields[key] = value
+}
+
+// interfaceValue converts the node to the types used when decoding into an interface{},
+// i.e. map[string]interface{} for objects and []interface{} for arrays.
+// With useNumber, numbers and sizes are converted to a Number rather than an int64 or float64
+func (node *hoconNode) interfaceValue(useNumber bool) interface{} {
+	switch node.nodeType {
+	case objectNode:
+		m := make(map[string]interface{}, len(node.keys))
+		for _, key := range node.keys {
+			m[key] = node.fields[key].interfaceValue(useNumber)
+		}
+		return m
+	case arrayNode:
+		s := make([]interface{}, 0, len(node.elements))
+		for _, element := range node.elements {
+			s = append(s, element.interfaceValue(useNumber))
+		}
+		return s
+	}
+	tokenValue := node.token.Value
+	switch node.token.Type {
+	case Integer, Float, Size:
+		if useNumber {
+			return Number(tokenValue)
+		}
+	}
+	switch node.token.Type {
+	case Boolean:
+		if val, err := strconv.ParseBool(tokenValue); err == nil {
+			return val
+		}
+	case Integer, Size:
+		if val, err := strconv.ParseInt(tokenValue, 10, 64); err == nil {
+			return val
+		}
+	case Float:
+		if val, err := strconv.ParseFloat(tokenValue, 64); err == nil {
+			return val
+		}
+	case Duration:
+		if val, err := time.ParseDuration(tokenValue + "ns"); err == nil {
+			return val
+		}
+	case TimePeriod:
+		if val, err := ParsePeriod(tokenValue); err == nil {
+			return val
+		}
+	}
+	return tokenValue
+}
+
+// valueType returns the type of the node's value
+func (node *hoconNode) valueType() ValueType {
+	switch node.nodeType {
+	case objectNode:
+		return ObjectValue
+	case arrayNode:
+		return ArrayValue
+	}
+	return tokenValueType(node.token.Type)
+}
+
+// tokenValueType returns the type of the value of a token
+func tokenValueType(tokenType HoconTokenType) ValueType {
+	switch tokenType {
+	case Boolean:
+		return BooleanValue
+	case Integer, Float:
+		return NumberValue
+	case Duration:
+		return DurationValue
+	case Size:
+		return SizeValue
+	case TimePeriod:
+		return PeriodValue
+	}
+	return StringValue
+}
+
+// splitPath splits a path expression such as foo.bar."hello.world" into its elements.
+//
+// Periods inside quoted strings are not separators and quoted strings may contain escapes.
+// An element may only be empty if it is quoted, so a..b or a path starting or ending with a period is invalid.
+func splitPath(path string) ([]string, error) {
+	var elements []string
+	var element strings.Builder
+	quoted := false
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '.':
+			if element.Len() == 0 && !quoted {
+				return nil, &ParserInvalidPathErr{Path: path}
+			}
+			elements = append(elements, element.String())
+			element.Reset()
+			quoted = false
+		case '"':
+			end := i + 1
+			for ; end < len(path) && path[end] != '"'; end++ {
+				if path[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(path) {
+				return nil, &ParserInvalidPathErr{Path: path}
+			}
+			s, err := unquote(path[i : end+1])
+			if err != nil {
+				return nil, &ParserInvalidPathErr{Path: path}
+			}
+			element.WriteString(s)
+			quoted = true
+			i = end
+		default:
+			element.WriteByte(path[i])
+		}
+	}
+	if element.Len() == 0 && !quoted {
+		return nil, &ParserInvalidPathErr{Path: path}
+	}
+	return append(elements, element.String()), nil
+}
+
+// indexPath appends the index of an array element to the path expression, e.g. servers[3]
+func indexPath(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
+
+// joinPath appends the key to the path expression, quoting the key unless it is a valid unquoted path element
+func joinPath(path, key string) string {
+	if key == "" || strings.IndexFunc(key, func(r rune) bool { return r == '.' || r == '/' || !isUnquotedRune(r) }) >= 0 {
+		key = strconv.Quote(key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,62 @@
+package aconf
+
+import (
+	"fmt"
+)
+
+// OriginKind tells how a value came to be set
+type OriginKind int
+
+const (
+	// OriginFile is a value set in the file being parsed
+	OriginFile OriginKind = iota
+	// OriginInclude is a value set in an included file
+	OriginInclude
+	// OriginEnv is a value substituted from an environment variable, for a substitution whose path is not set in
+	// the document
+	OriginEnv
+	// OriginFallback is a value set in a fallback layer, see Config.WithFallback
+	OriginFallback
+	// OriginSubstitution is a value copied from the path named by a substitution, such as ${db.host}
+	OriginSubstitution
+)
+
+var originKindNames = [...]string{
+	OriginFile:         "file",
+	OriginInclude:      "include",
+	OriginEnv:          "env",
+	OriginFallback:     "fallback",
+	OriginSubstitution: "substitution",
+}
+
+func (k OriginKind) String() string {
+	if k < 0 || int(k) >= len(originKindNames) {
+		return fmt.Sprintf("OriginKind(%d)", int(k))
+	}
+	return originKindNames[k]
+}
+
+// ConfigOrigin tells where a value was set: how, and the location of the value, or of the substitution for a
+// value copied from a path or from an environment variable
+type ConfigOrigin struct {
+	Kind OriginKind
+	LexLocation
+}
+
+// String describes the origin as file:line:col, the same way as the locations of errors, followed by its kind
+// unless the value was set in the file being parsed, e.g. common.conf:3:1 (include)
+func (o ConfigOrigin) String() string {
+	s := fmt.Sprintf("%d:%d", o.Line(), o.Column())
+	if o.FileName() != "" {
+		s = o.FileName() + ":" + s
+	}
+	if o.Kind != OriginFile {
+		s += " (" + o.Kind.String() + ")"
+	}
+	return s
+}
+
+// origin returns where the node was set
+func (node *hoconNode) origin() ConfigOrigin {
+	return ConfigOrigin{node.source, node.LexLocation}
+}
@@ -4,105 +4,482 @@ import (
 	"io"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 	"unicode/utf8"
 )
 
+// Unmarshaler is implemented by types which decode themselves from a HOCON value, e.g. to accept values of
+// different types. The value is passed the same way as it is decoded into an interface{}, i.e. as a
+// map[string]interface{}, []interface{}, string, bool, int64, float64, time.Duration or Period, or a Number for
+// numbers with the UseNumber option.
+type Unmarshaler interface {
+	UnmarshalHOCON(value interface{}) error
+}
+
+// HoconParser decodes HOCON into Go values, using the options set in its fields and the converters registered
+// with RegisterConverter.
+//
+// A HoconParser holds no state between calls, so it can be reused, and used by several goroutines at once
+// as long as its fields are not changed and no converters are registered meanwhile.
 type HoconParser struct {
-	tokens            []HoconToken
-	sliceBracketStack []int
-	insideSlice       bool
-	returnControl     bool
+	// IncludeResolver opens the files named in include statements. If nil, they are opened using os.Open
+	IncludeResolver IncludeResolver
+	// DisallowUnknownKeys makes keys which do not match any field of the target struct an error
+	DisallowUnknownKeys bool
+	// StrictTypes turns off the automatic type conversions recommended by the HOCON spec, so that values
+	// only decode into fields of their own type, e.g. "42" is then an error for an int field
+	StrictTypes bool
+	// LookupEnv returns the value of an environment variable for substitutions such as ${HOME}, or ${?HOME} which
+	// is left out if the variable is not set, when no key of that path is set in the document.
+	// os.LookupEnv looks up the variables of the process. If nil, only the paths set in the document are substituted
+	LookupEnv func(key string) (string, bool)
+	// UseNumber decodes numbers into an interface{} as a Number, which keeps them as they were written
+	UseNumber bool
+
+	converters map[converterKey]ConverterFunc
+}
+
+// parseState holds the state of a single call to Parse or ParseProperties, along with a copy of the options of
+// the HoconParser so that they cannot change during the call
+type parseState struct {
+	HoconParser
+
+	// lexer is read one token at a time as the tree is built, and tokens holds the tokens read ahead of the parser
+	lexer  *HoconLexer
+	tokens []HoconToken
+	syntax syntaxCheck
+	// fileName is the name of the file being parsed, if known, which the names of included files are relative to
+	fileName string
+	// includes holds the names of the files being included, to detect include cycles
+	includes []string
+	// errs holds the problems found so far, parsing carries on after each of them to find the rest
+	errs ErrorList
+	// metadata records the keys which are decoded, if it is not nil
+	metadata *Metadata
+}
+
+// newParseState returns the state for a single call to the parser
+func (parser *HoconParser) newParseState() *parseState {
+	state := &parseState{}
+	if parser != nil {
+		state.HoconParser = *parser
+	}
+	return state
 }
 
+// Parse decodes the HOCON read from the reader into v.
+//
+// Parsing does not stop at the first problem found. All the syntax errors and the values which cannot be decoded
+// are reported together as an ErrorList, in which case v holds the values which could be decoded.
 func (parser *HoconParser) Parse(hoconContentReader io.Reader, v interface{}) error {
+	return parser.parse(hoconContentReader, v, nil)
+}
 
-	var err error
+// parse decodes the HOCON read from the reader into v, recording what was decoded in the metadata if it is not nil
+func (parser *HoconParser) parse(hoconContentReader io.Reader, v interface{}, metadata *Metadata) error {
 
-	//lexer := HoconLexer{Reader: hoconContentReader}
-	lexer, err := NewLexer(hoconContentReader)
-	if parser.tokens, err = lexer.Run(); err != nil || parser.tokens == nil {
+	state := parser.newParseState()
+	state.metadata = metadata
+	root, err := state.parseTree(hoconContentReader)
+	// The syntax errors are more useful than an invalid target error, which is left out if there are any
+	if err != nil && (root == nil || !isDecodeTarget(v)) {
 		return err
 	}
+	// An empty document is an empty object, so that the required keys are reported
+	if root == nil {
+		root = newObjectNode(LexLocation{fileName: readerName(hoconContentReader), lineNumber: 1, columnNumber: 1})
+	}
 
-	if err = validateSyntax(parser.tokens); err != nil {
+	// The errors found in decoding are added to the syntax errors, which the state holds already
+	return state.unmarshal(root, v, "")
+}
+
+// ParsePath decodes only the value at the path expression, such as axlrate.imdg, of the HOCON read from the reader
+// into v, so that v need not mirror the rest of the tree.
+//
+// Errors report the full paths of the keys. If nothing is set at the path, a *ParserMissingKeyErr is returned.
+func (parser *HoconParser) ParsePath(hoconContentReader io.Reader, path string, v interface{}) error {
+	config, err := parser.ParseConfig(hoconContentReader)
+	if err != nil {
 		return err
 	}
+	return config.Decode(path, v)
+}
+
+// ParseProperties decodes the Java properties read from the reader into v.
+//
+// Keys such as a.b.c are mapped to nested objects, the same way as the path expressions of a HOCON file.
+func (parser *HoconParser) ParseProperties(propertiesReader io.Reader, v interface{}) error {
+
+	var err error
 
-	if err = parser.unmarshal(v); err != nil {
+	root, err := parseProperties(propertiesReader)
+	if err != nil {
 		return err
 	}
 
-	/*
-		if m, err = buildMap(tokens); err != nil {
-			return nil, err
-		}
-	*/
+	if err = parser.newParseState().unmarshal(root, v, ""); err != nil {
+		return err
+	}
 
 	return err
 }
 
-func validateSyntax(tokens []HoconToken) error {
+// parseTree builds the tree of the contents of the HOCON read from the reader, reading the tokens from the lexer
+// as they are needed. A nil tree is returned if the input has no tokens.
+//
+// The tree holds whatever could be parsed, even if errors are found, so that decoding it can find any further errors.
+func (parser *parseState) parseTree(hoconContentReader io.Reader) (*hoconNode, error) {
+
 	var err error
-	// Check for Balanced Parentheses
-	if err = checkBalancedParens(tokens); err != nil {
-		return err
+
+	parser.fileName = readerName(hoconContentReader)
+	if parser.lexer, err = NewLexer(hoconContentReader); err != nil {
+		return nil, err
+	}
+	parser.errs = nil
+	if _, ok := parser.peek(0); !ok {
+		return nil, parser.errs.err()
 	}
 
-	// Validate if closing braces are only preceded by NL or a Value
-	for i, token := range tokens {
-		if token.Type == RightBrace && !(tokens[i-1].Type == Text || tokens[i-1].Type == NewLine || tokens[i-1].Type == RightBracket) {
-			err = &LexInvalidTokenErr{tokens[i-1].Value, tokens[i-1].LexLocation}
-			break
+	root := parser.buildTree()
+	// The substitutions in included files are resolved along with those of the including file
+	if len(parser.includes) == 0 {
+		parser.resolveSubstitutions(root)
+	}
+	// The syntax checks find the unclosed braces only at the end of the input, after the errors found in building
+	// the tree, so the errors are put in the order of their locations
+	parser.errs.sortByLocation()
+	parser.errs.dedupe()
+	return root, parser.errs.err()
+}
+
+// syntaxCheck finds the errors which show in the sequence of tokens, checking each token as it is read
+type syntaxCheck struct {
+	previous *HoconToken
+	// parens holds the braces, brackets and parentheses which are not closed yet. Once a closing one without an
+	// opening one is found, they are not checked any further
+	parens   []HoconToken
+	stopped  bool
+	brackets []HoconTokenType
+}
+
+// next checks the token which follows the tokens checked already, adding any errors found to the list
+func (check *syntaxCheck) next(token HoconToken, errs *ErrorList) {
+	previous := check.previous
+	check.previous = &token
+
+	// Check for Balanced Parentheses
+	if !check.stopped {
+		switch token.Type {
+		case LeftBrace, LeftBracket, LeftParen:
+			r, size := utf8.DecodeRuneInString(token.Value)
+			if r == utf8.RuneError && (size == 0 || size == 1) {
+				errs.add(&ParserInvalidRuneErr{token.LexLocation})
+				check.stopped = true
+			}
+			check.parens = append(check.parens, token)
+		case RightBrace, RightBracket, RightParen:
+			// A closing token without an opening one is reported where it is found
+			if l := len(check.parens); l == 0 {
+				errs.add(&ParserUnbalancedParenthesesErr{token.LexLocation})
+				check.stopped = true
+			} else {
+				check.parens = check.parens[:l-1]
+			}
 		}
 	}
 
-	// Validate if opening square-brackets are preceded by an equals or colon token
-	for i, token := range tokens {
-		if token.Type == LeftBracket && !(tokens[i-1].Type == Equals || tokens[i-1].Type == Colon) {
-			err = &ParserInvalidArrayErr{tokens[i-1].Value, tokens[i-1].LexLocation}
-			break
+	// Validate if closing braces are only preceded by NL, a Value or the end of a block
+	if previous != nil && token.Type == RightBrace && !isValueTokenType(previous.Type) && !(previous.Type == NewLine || previous.Type == RightBracket || previous.Type == LeftBrace || previous.Type == RightBrace || previous.Type == RightParen || previous.Type == Other) {
+		errs.add(&LexInvalidTokenErr{previous.Value, previous.LexLocation})
+	}
+
+	// Validate if opening square-brackets are preceded by an equals or colon token, or start an element of an array
+	insideArray := len(check.brackets) > 0 && check.brackets[len(check.brackets)-1] == LeftBracket
+	switch token.Type {
+	case LeftBrace:
+		check.brackets = append(check.brackets, LeftBrace)
+	case RightBrace, RightBracket:
+		if len(check.brackets) > 0 {
+			check.brackets = check.brackets[:len(check.brackets)-1]
+		}
+	case LeftBracket:
+		check.brackets = append(check.brackets, LeftBracket)
+		if previous != nil && (previous.Type == Equals || previous.Type == Colon) {
+			return
 		}
+		if previous != nil && insideArray && (previous.Type == LeftBracket || previous.Type == Comma || previous.Type == NewLine) {
+			return
+		}
+		if previous == nil {
+			previous = &token
+		}
+		errs.add(&ParserInvalidArrayErr{previous.Value, previous.LexLocation})
 	}
+}
 
-	// Validate there are no dangling keys i.e. keys followed by nothing
+// end checks the end of the tokens, adding any errors found to the list
+func (check *syntaxCheck) end(errs *ErrorList) {
+	// The innermost opening token which is never closed is reported
+	if l := len(check.parens); l != 0 && !check.stopped {
+		errs.add(&ParserUnbalancedParenthesesErr{check.parens[l-1].LexLocation})
+	}
+}
 
-	// Array data type validation
-	// Determine Type of array based on first element in it
-	// If all are not same, then error out
+func isValueTokenType(tokenType HoconTokenType) bool {
+	switch tokenType {
+	case Boolean, Integer, Float, Duration, Size, TimePeriod, Text, Substitution:
+		return true
+	}
+	return false
+}
 
+// buildTree consumes the tokens and creates the tree of objects, arrays and values described by them.
+//
+// Path expressions in keys are expanded into nested objects and duplicate keys are merged.
+// The errors found are added to the parser's errors, leaving out the fields and elements in which they are found.
+func (parser *parseState) buildTree() *hoconNode {
+	root := newObjectNode(LexLocation{lineNumber: 1, columnNumber: 1})
+	if token, ok := parser.peek(0); ok {
+		root.fileName = token.fileName
+	}
+	parser.skipNewLines()
+	// The braces around the root object are optional
+	token, braces := parser.peek(0)
+	braces = braces && token.Type == LeftBrace
+	if braces {
+		root.LexLocation = token.LexLocation
+		parser.advance()
+	}
+	parser.errs.add(parser.parseObject(root, braces))
+	return root
+}
+
+// parseObject adds the fields found in the tokens to the object node.
+//
+// If closed is set, it assumes that the '{' has already been consumed and returns after consuming the matching '}'
+func (parser *parseState) parseObject(node *hoconNode, closed bool) error {
+	for token, ok := parser.peek(0); ok; token, ok = parser.peek(0) {
+		switch token.Type {
+		case NewLine, Comma:
+			parser.advance()
+		case RightBrace:
+			parser.advance()
+			if !closed {
+				parser.errs.add(&ParserUnbalancedParenthesesErr{token.LexLocation})
+				continue
+			}
+			return nil
+		case Key, Integer, Float:
+			if err := parser.parseField(node, token); err != nil {
+				parser.errs.add(err)
+				parser.skipField()
+			}
+		case Other:
+			// The lexer has reported the invalid token already
+			parser.advance()
+			parser.skipField()
+		default:
+			parser.errs.add(&ParserInvalidTokenTypeErr{token})
+			parser.advance()
+			parser.skipField()
+		}
+	}
+	if closed {
+		return &ParserUnbalancedParenthesesErr{node.LexLocation}
+	}
+	return nil
+}
+
+// parseField consumes a key and its value, or an include statement, and adds them to the object node
+func (parser *parseState) parseField(node *hoconNode, token HoconToken) error {
+	if parser.isIncludeStatement(token) {
+		parser.advance()
+		included, err := parser.parseInclude(token)
+		if err != nil {
+			return err
+		}
+		node.merge(included)
+		return nil
+	}
+	path, err := splitPath(token.Value)
+	if err != nil {
+		parser.advance()
+		return withLocation(err, token.LexLocation)
+	}
+	parser.advance()
+	if parser.peekIs(0, Equals) || parser.peekIs(0, Colon) {
+		parser.advance()
+	}
+	// Whatever could be parsed of a value with errors in it is kept, to find any errors in decoding it
+	value, err := parser.parseValue(token)
+	if value != nil {
+		node.set(path, value)
+	}
 	return err
 }
 
+// parseArray adds the elements found in the tokens to the array node.
+//
+// Assumes that the '[' has already been consumed and returns after consuming the matching ']'
+func (parser *parseState) parseArray(node *hoconNode) error {
+	for token, ok := parser.peek(0); ok; token, ok = parser.peek(0) {
+		switch token.Type {
+		case NewLine, Comma:
+			parser.advance()
+		case RightBracket:
+			parser.advance()
+			return nil
+		case RightBrace:
+			// The '}' closes the enclosing object, leaving the array unclosed
+			return &ParserUnbalancedParenthesesErr{node.LexLocation}
+		default:
+			value, err := parser.parseValue(token)
+			if value != nil {
+				node.elements = append(node.elements, value)
+			}
+			if err != nil {
+				parser.errs.add(err)
+				parser.skipField()
+			}
+		}
+	}
+	return &ParserUnbalancedParenthesesErr{node.LexLocation}
+}
+
+// parseValue consumes the tokens making up the value of a field or an array element.
+// The previous token is used to report a missing value.
+//
+// A nil node is returned for an invalid token, which has been reported by the lexer already
+func (parser *parseState) parseValue(previous HoconToken) (*hoconNode, error) {
+	parser.skipNewLines()
+	token, ok := parser.peek(0)
+	if !ok {
+		return nil, &ParserInvalidTokenTypeErr{previous}
+	}
+	switch token.Type {
+	case LeftBrace:
+		parser.advance()
+		node := newObjectNode(token.LexLocation)
+		return node, parser.parseObject(node, true)
+	case LeftBracket:
+		parser.advance()
+		node := newArrayNode(token.LexLocation)
+		return node, parser.parseArray(node)
+	case Boolean, Integer, Float, Duration, Size, TimePeriod, Text:
+		parser.advance()
+		return newValueNode(token), nil
+	case Substitution:
+		// Substitutions are resolved once the tree is built, as they may refer to keys which are set further on
+		parser.advance()
+		return newValueNode(token), nil
+	case Other:
+		parser.advance()
+		return nil, nil
+	}
+	return nil, &ParserInvalidTokenTypeErr{token}
+}
+
+// skipField discards the tokens up to the end of the field or array element in which an error was found, i.e. up to
+// the next newline or comma outside of any nested object or array, or the end of the enclosing object or array
+func (parser *parseState) skipField() {
+	depth := 0
+	for token, ok := parser.peek(0); ok; token, ok = parser.peek(0) {
+		switch token.Type {
+		case LeftBrace, LeftBracket:
+			depth++
+		case RightBrace, RightBracket:
+			if depth == 0 {
+				return
+			}
+			depth--
+		case NewLine, Comma:
+			if depth == 0 {
+				return
+			}
+		}
+		parser.advance()
+	}
+}
+
+func (parser *parseState) skipNewLines() {
+	for parser.peekIs(0, NewLine) {
+		parser.advance()
+	}
+}
+
+// peek returns the token i places after the next token to be consumed, reading the tokens up to it from the lexer
+// if they have not been read yet. It returns false if the input ends before that token
+func (parser *parseState) peek(i int) (HoconToken, bool) {
+	for len(parser.tokens) <= i && parser.lexer != nil {
+		token, err := parser.lexer.Next()
+		if err != nil && err != io.EOF {
+			parser.errs.add(err)
+		}
+		// Invalid tokens are kept, anything else ends the input
+		if err != nil && (err == io.EOF || token.Type != Other) {
+			parser.syntax.end(&parser.errs)
+			parser.lexer = nil
+			break
+		}
+		parser.syntax.next(token, &parser.errs)
+		parser.tokens = append(parser.tokens, token)
+	}
+	if i < len(parser.tokens) {
+		return parser.tokens[i], true
+	}
+	return HoconToken{}, false
+}
+
+// peekIs reports whether the token i places after the next token to be consumed has the given type
+func (parser *parseState) peekIs(i int, tokenType HoconTokenType) bool {
+	token, ok := parser.peek(i)
+	return ok && token.Type == tokenType
+}
+
+// advance consumes the next token, which must have been peeked at
+func (parser *parseState) advance() {
+	parser.tokens = parser.tokens[1:]
+}
+
 /*
+unmarshal decodes the tree into v, which must be a non-nil pointer
 
- */
-func (parser *HoconParser) unmarshal(v interface{}) error {
-	var err error
+Decoding carries on past the values which cannot be decoded, and returns the errors for all of them along with
+any errors the parser holds already
+*/
+func (parser *parseState) unmarshal(node *hoconNode, v interface{}, path string) error {
 	// Check if rv kind is pointer, if not, then error out
 	rv := reflect.ValueOf(v)
-	if rv.IsNil() || rv.Kind() != reflect.Ptr {
-		return &ParserInvalidTargetErr{got: rv.Kind().String(), want: reflect.Ptr.String()}
+	if !isDecodeTarget(v) {
+		return &ParserInvalidTargetErr{Got: rv.Kind().String(), Want: reflect.Ptr.String()}
 	}
-	err = parser.decode(rv.Elem(), rv.Elem())
-	return err
+	parser.errs.add(parser.decode(node, rv.Elem(), path))
+	return parser.errs.err()
+}
+
+// isDecodeTarget reports whether v is a non-nil pointer, which values can be decoded into
+func isDecodeTarget(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && !rv.IsNil()
 }
 
 func (parser *HoconParser) FieldByName(fieldName string, v reflect.Value) reflect.Value {
 	var nv reflect.Value
 	// First try to lookup the field directly in the Value
-	if nv = v.FieldByName(fieldName); nv.IsValid() {
-		return nv
+	if sf, ok := v.Type().FieldByName(fieldName); ok {
+		return fieldByIndex(v, sf.Index)
 	}
 	// If not found then try to look it up based on tags
 	t := v.Type()
 	for i := 0; i < t.NumField(); i++ {
 		// Does fieldName match the tag value ?
-		sTag := t.Field(i).Tag
-		if sName, ok := sTag.Lookup("hocon"); ok {
+		if sName, _ := hoconTag(t.Field(i)); sName != "" {
 			if sName == fieldName {
 				// Return the Value.Field based on this sName
-				nv = v.FieldByName(t.Field(i).Name)
+				nv = v.Field(i)
 				break
 			}
 		}
@@ -110,269 +487,404 @@ func (parser *HoconParser) FieldByName(fieldName string, v reflect.Value) reflec
 	return nv
 }
 
-// function decode runs the main loop iterating over tokens and invoking handler functions
-func (parser *HoconParser) decode(v reflect.Value, pv reflect.Value) error {
-	var err error
+// hoconTag returns the key and the options of the hocon tag of a struct field, e.g. "port" for hocon:"port" or
+// an empty key and the option remain for hocon:",remain"
+func hoconTag(sf reflect.StructField) (key string, options []string) {
+	tag, ok := sf.Tag.Lookup("hocon")
+	if !ok {
+		return "", nil
+	}
+	key, rest, _ := strings.Cut(tag, ",")
+	if rest != "" {
+		options = strings.Split(rest, ",")
+	}
+	return key, options
+}
 
-	for {
-		token := parser.tokens[0]
-		//fmt.Println("tokenValue =", token.Value)
-		//fmt.Println("v =", v.Type(), "pv =", pv.Type())
+// fieldKey returns the key of the struct field in the object node, i.e. the name of the field or the key of its
+// hocon tag, and whether the node sets it. The tag of a promoted field is not used
+func fieldKey(node *hoconNode, sf reflect.StructField) (string, bool) {
+	if _, ok := node.fields[sf.Name]; ok {
+		return sf.Name, true
+	}
+	key, _ := hoconTag(sf)
+	if key == "" || len(sf.Index) > 1 {
+		return sf.Name, false
+	}
+	_, ok := node.fields[key]
+	return key, ok
+}
 
-		switch token.Type {
-		case Key:
-			//v = parser.FieldByName(token.Value, pv)
-			v = parser.FieldByName(token.Value, pv)
-		case Equals, Colon, Comma:
-		case NewLine:
-		case Boolean, Integer, Duration, Float, Text:
-			if err = parser.setValue(v, token); err != nil {
-				return err
-			}
-		case LeftBrace:
-			/*
-				Use root/parent value pv = v
-				And then invoke decode object
-			*/
-			parser.tokens = parser.tokens[1:]
-			if err = parser.decodeObject(v, v); err != nil {
-				return err
-			}
-		case LeftBracket:
-			// start of an slice
-			// create the slice
-			nv := reflect.MakeSlice(v.Type(), 0, 0)
-			v.Set(nv)
-			// Call decodeSequence
-			parser.tokens = parser.tokens[1:]
-			if err = parser.decodeSequence(v, v); err != nil {
-				return err
-			}
-		case RightBrace:
-		case RightBracket:
+// remainField returns the field of the struct type tagged with the option remain, which collects the keys that
+// match no other field
+func remainField(t reflect.Type) (reflect.StructField, bool) {
+	for _, sf := range reflect.VisibleFields(t) {
+		if _, options := hoconTag(sf); sf.IsExported() && containsString(options, "remain") {
+			return sf, true
 		}
+	}
+	return reflect.StructField{}, false
+}
 
-		// Advance the tokens
-		numTokens := len(parser.tokens)
-		if numTokens <= 1 {
-			break
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
 		}
-		parser.tokens = parser.tokens[1:]
+	}
+	return false
+}
 
+// fieldByIndex returns the possibly promoted field with the index sequence, allocating any nil embedded struct pointers
+// on the way. An invalid Value is returned if an embedded pointer is nil and cannot be set.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
 	}
-	return err
+	return v
 }
 
-// function decodeObject is invoked when the caller sees a '{' and recurses till a matching '}' is found.
-//
-// Assumes that the '{' has already been parsed
-func (parser *HoconParser) decodeObject(v reflect.Value, pv reflect.Value) error {
-	var err error
+// function decode dispatches the node to the handler function for its type.
+// The path is the path expression of the node, used to report errors.
+func (parser *parseState) decode(node *hoconNode, v reflect.Value, path string) error {
+	if ok, err := parser.convert(node, v, path); ok {
+		return err
+	}
+	if v.Type() == configType {
+		v.Set(reflect.ValueOf(Config{node, parser.HoconParser, path}))
+		return nil
+	}
+	if v.Kind() != reflect.Ptr && v.CanAddr() && reflect.PtrTo(v.Type()).Implements(unmarshalerType) {
+		if err := v.Addr().Interface().(Unmarshaler).UnmarshalHOCON(node.interfaceValue(parser.UseNumber)); err != nil {
+			return &ParserUnmarshalErr{path, err, node.LexLocation}
+		}
+		return nil
+	}
+	switch {
+	case v.Kind() == reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return parser.decode(node, v.Elem(), path)
+	case v.Kind() == reflect.Interface && v.NumMethod() == 0:
+		v.Set(reflect.ValueOf(node.interfaceValue(parser.UseNumber)))
+		return nil
+	}
 
-	lCount := 1
-	rCount := 0
-	for {
-		token := parser.tokens[0]
-		//fmt.Println("v =", v.Type(), "pv =", pv.Type())
-		switch token.Type {
-		case Key:
-			v = parser.FieldByName(token.Value, pv)
-		case Equals, Colon, Comma:
-		case NewLine:
-		case Boolean, Integer, Duration, Float, Text:
-			if err = parser.setValue(v, token); err != nil {
-				return err
+	switch node.nodeType {
+	case objectNode:
+		switch v.Kind() {
+		case reflect.Slice, reflect.Array:
+			return parser.decodeSequence(node, v, path)
+		case reflect.Struct, reflect.Map:
+			return parser.decodeObject(node, v, path)
+		}
+	case arrayNode:
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			return parser.decodeSequence(node, v, path)
+		}
+	default:
+		return parser.setValue(v, node.token, path)
+	}
+	return &ParserTypeMismatchErr{path, v.Type().String(), node.valueType(), node.LexLocation}
+}
+
+// function decodeObject decodes the fields of an object node into a struct or into a map with string keys.
+// The errors found in decoding the fields are added to the parser's errors.
+func (parser *parseState) decodeObject(node *hoconNode, v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		failed := map[string]bool{}
+		remain, hasRemain := remainField(v.Type())
+		var remaining *hoconNode
+		for _, key := range node.keys {
+			fv := parser.FieldByName(key, v)
+			if hasRemain && (!fv.IsValid() || key == remain.Name) {
+				if remaining == nil {
+					remaining = newObjectNode(node.LexLocation)
+				}
+				remaining.setField(key, node.fields[key])
+				continue
 			}
-		case LeftBrace:
-			lCount++
-			parser.tokens = parser.tokens[1:] //this is to avoid the infinite loop
-			if err = parser.decodeObject(v, v); err != nil {
-				return err
+			if !fv.IsValid() && parser.DisallowUnknownKeys {
+				parser.errs.add(&ParserUnknownKeyErr{joinPath(path, key), node.fields[key].LexLocation})
 			}
-			// We have returned from decodeObject, so update the rCount otherwise we never return
-			rCount++
-		case LeftBracket:
-			// start of an slice
-			// create the slice
-			nv := reflect.MakeSlice(v.Type(), 0, 0)
-			v.Set(nv)
-			// Call decodeSequence
-			parser.tokens = parser.tokens[1:] //this is to avoid the infinite loop
-			if err = parser.decodeSequence(v, v); err != nil {
-				return err
+			if !fv.IsValid() || !fv.CanSet() {
+				if parser.metadata != nil {
+					parser.metadata.Unused = append(parser.metadata.Unused, joinPath(path, key))
+				}
+				continue
 			}
-		case RightBrace:
-			rCount++
-		case RightBracket:
+			if parser.metadata != nil {
+				parser.metadata.addKey(joinPath(path, key), node.fields[key])
+			}
+			n := len(parser.errs)
+			parser.errs.add(parser.decode(node.fields[key], fv, joinPath(path, key)))
+			failed[key] = len(parser.errs) > n
 		}
-		// Advance the tokens
-		numTokens := len(parser.tokens)
-		if numTokens <= 1 {
-			break
+		if parser.metadata != nil {
+			parser.metadata.addUnset(node, v.Type(), path)
 		}
-
-		if lCount == rCount {
-			// Reached end of block
-			break
+		if remaining != nil {
+			if fv := fieldByIndex(v, remain.Index); fv.IsValid() && fv.CanSet() {
+				parser.errs.add(parser.decode(remaining, fv, path))
+			}
+		}
+		parser.validateUnset(node, v, path)
+		parser.validateStruct(node, v, path, failed)
+	case reflect.Map:
+		t := v.Type()
+		if t.Key().Kind() != reflect.String {
+			return &ParserTypeMismatchErr{path, t.String(), node.valueType(), node.LexLocation}
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(t, len(node.keys)))
+		}
+		for _, key := range node.keys {
+			if parser.metadata != nil {
+				parser.metadata.addKey(joinPath(path, key), node.fields[key])
+			}
+			elem := reflect.New(t.Elem()).Elem()
+			if err := parser.decode(node.fields[key], elem, joinPath(path, key)); err != nil {
+				parser.errs.add(err)
+				continue
+			}
+			v.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
 		}
-		parser.tokens = parser.tokens[1:]
-
 	}
-	return err
+	return nil
 }
 
-// function decodeSequence handles aggregate types arrays/slices and is called by the decode function.
-// It can call the function decodeObject, if the aggregate type is an object
-// assumes that '[' has already been scanned and the first character is the one after that
-func (parser *HoconParser) decodeSequence(v reflect.Value, pv reflect.Value) error {
+// function decodeSequence decodes the elements of an array node into a slice or into a Go array.
+// Each element is decoded in turn, so the elements may themselves be arrays, slices, objects or structs.
+//
+// An object node with numeric keys such as { "0" : a, "1" : b } is decoded as if it were the array [a, b]
+// A Go array must have as many elements as the array node, otherwise the elements which fit are decoded and
+// a *ParserArrayLengthErr is returned.
+// The errors found in decoding the elements are added to the parser's errors.
+func (parser *parseState) decodeSequence(node *hoconNode, v reflect.Value, path string) error {
 	var err error
 
-	lCount := 1
-	rCount := 0
-	index := 0
-	for {
-		token := parser.tokens[0]
-		//fmt.Println("v =", v.Type(), "pv =", pv.Type())
-		switch token.Type {
-		case Key:
-			v = parser.FieldByName(token.Value, pv)
-		case Equals, Colon, Comma, NewLine:
-			//Ok
-		case Boolean, Integer, Duration, Float, Text:
-			// Allocate a new element
-			elem := reflect.New(pv.Type().Elem()).Elem()
-			// Append the element
-
-			pv.Set(reflect.Append(pv, elem))
-			v = pv.Index(pv.Len() - 1)
-			//v = pv.Index(index)
-			if err = parser.setValue(v, token); err != nil {
-				return err
-			}
-			index++
-		case LeftBrace:
-			// Allocate a new element
-			elem := reflect.New(pv.Type().Elem()).Elem()
-			pv.Set(reflect.Append(pv, elem))
-			v = pv.Index(pv.Len() - 1)
-			parser.tokens = parser.tokens[1:]
-			if err = parser.decodeObject(v, v); err != nil {
-				return err
-			}
-
-		case LeftBracket:
-			lCount++
-			// start of an slice
-			// create the slice
-			nv := reflect.MakeSlice(v.Type(), 0, 0)
-			v.Set(nv)
-			// Recursive call
-			if err = parser.decodeSequence(v, v); err != nil {
-				return err
-			}
-			rCount++
-		case RightBrace:
-
-		case RightBracket:
-			rCount++
+	elements := node.elements
+	if node.nodeType == objectNode {
+		var ok bool
+		if elements, ok = node.indexedElements(); !ok {
+			return &ParserTypeMismatchErr{path, v.Type().String(), node.valueType(), node.LexLocation}
 		}
-		// Advance the tokens
-		numTokens := len(parser.tokens)
-		if numTokens <= 1 {
-			break
+	}
+	nv := v
+	if v.Kind() == reflect.Slice {
+		nv = reflect.MakeSlice(v.Type(), len(elements), len(elements))
+	} else {
+		nv = reflect.New(v.Type()).Elem()
+		if len(elements) != v.Len() {
+			err = &ParserArrayLengthErr{path, v.Len(), len(elements), node.LexLocation}
 		}
-
-		if lCount == rCount {
-			// Reached end of block
-			break
+		if len(elements) > v.Len() {
+			elements = elements[:v.Len()]
+		}
+	}
+	for i, element := range elements {
+		if parser.metadata != nil {
+			parser.metadata.addKey(indexPath(path, i), element)
 		}
-		parser.tokens = parser.tokens[1:]
+		parser.errs.add(parser.decode(element, nv.Index(i), indexPath(path, i)))
 	}
+	v.Set(nv)
 	return err
 }
 
-func (parser *HoconParser) getNextToken(startIndex int) (HoconToken, int) {
-	var token HoconToken
-	var index int
-	for i, t := range parser.tokens[startIndex:] {
-		if t.Type != NewLine {
-			token = t
-			index = i
-			break
+var (
+	durationType    = reflect.TypeOf(time.Duration(0))
+	unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	periodType      = reflect.TypeOf(Period{})
+	timeType        = reflect.TypeOf(time.Time{})
+	numberType      = reflect.TypeOf(Number(""))
+	configType      = reflect.TypeOf(Config{})
+	// timeLayouts are the formats accepted for time.Time values, i.e. RFC 3339 timestamps and dates
+	timeLayouts = []string{time.RFC3339Nano, "2006-01-02"}
+)
+
+func (parser *HoconParser) setValue(v reflect.Value, token HoconToken, path string) error {
+	switch v.Type() {
+	case periodType:
+		return parser.setPeriod(v, token)
+	case timeType:
+		return parser.setTime(v, token)
+	case numberType:
+		return parser.setNumber(v, token, path)
+	case durationType:
+		if token.Type == Integer || token.Type == Float || token.Type == Text {
+			return parser.setDuration(v, token)
 		}
 	}
-	return token, index
-}
-
-func (parser *HoconParser) setValue(v reflect.Value, token HoconToken) error {
-	var err error
 
+	kind := v.Kind()
+	if !parser.StrictTypes {
+		token = convertToken(token, kind)
+	}
 	tokenValue := token.Value
-	switch token.Type {
-	case Boolean:
+	switch {
+	case token.Type == Boolean && kind == reflect.Bool:
 		val, err := strconv.ParseBool(tokenValue)
 		if err != nil {
-			return err
-		}
-		if v.IsValid() && v.Kind() == reflect.Bool {
-			v.SetBool(val)
+			return &ParserValueConversionErr{token, v.Type().String(), err}
 		}
-	case Integer:
+		v.SetBool(val)
+	case (token.Type == Integer || token.Type == Size) && isIntKind(kind):
 		val, err := strconv.ParseInt(tokenValue, 10, 64)
-		if err != nil {
-			return err
+		if err != nil || v.OverflowInt(val) {
+			return &ParserValueConversionErr{token, v.Type().String(), err}
 		}
-		if v.IsValid() && v.Kind() == reflect.Int64 {
-			v.SetInt(val)
+		v.SetInt(val)
+	case (token.Type == Integer || token.Type == Size) && isUintKind(kind):
+		val, err := strconv.ParseUint(tokenValue, 10, 64)
+		if err != nil || v.OverflowUint(val) {
+			return &ParserValueConversionErr{token, v.Type().String(), err}
 		}
-	case Float:
+		v.SetUint(val)
+	case (token.Type == Integer || token.Type == Float) && (kind == reflect.Float32 || kind == reflect.Float64):
 		val, err := strconv.ParseFloat(tokenValue, 64)
-		if err != nil {
-			return err
+		if err != nil || v.OverflowFloat(val) {
+			return &ParserValueConversionErr{token, v.Type().String(), err}
 		}
-		if v.IsValid() && v.Kind() == reflect.Float64 {
-			v.SetFloat(val)
-		}
-	case Duration:
+		v.SetFloat(val)
+	case token.Type == Duration && kind == reflect.Int64:
 		val, err := time.ParseDuration(tokenValue + "ns")
 		if err != nil {
-			return err
+			return &ParserValueConversionErr{token, durationType.String(), err}
 		}
-		if v.IsValid() && v.Kind() == reflect.Int64 {
-			v.SetInt(int64(val))
+		v.SetInt(int64(val))
+	case token.Type == TimePeriod && v.Type() == durationType:
+		val, err := ParsePeriod(tokenValue)
+		if err != nil {
+			return withLocation(err, token.LexLocation)
 		}
-	case Text:
-		if v.IsValid() && v.Kind() == reflect.String {
-			v.SetString(tokenValue)
+		// Only a period without years or months has a fixed length, which can be used as a time.Duration
+		d, ok := val.Duration()
+		if !ok {
+			return &ParserValueConversionErr{token, durationType.String(), nil}
 		}
+		v.SetInt(int64(d))
+	case token.Type == Text && kind == reflect.String:
+		v.SetString(tokenValue)
+	default:
+		return &ParserTypeMismatchErr{path, v.Type().String(), tokenValueType(token.Type), token.LexLocation}
 	}
-	return err
+	return nil
 }
 
-func checkBalancedParens(tokens []HoconToken) error {
+// convertToken applies the automatic type conversions recommended by the HOCON spec, for a value to be decoded
+// into the given kind: strings to numbers and booleans, and numbers, booleans and values with units to strings.
+// The token is returned unchanged when no conversion applies
+func convertToken(token HoconToken, kind reflect.Kind) HoconToken {
+	switch {
+	case token.Type == Text && kind == reflect.Bool:
+		switch token.Value {
+		case "true", "yes", "on":
+			return HoconToken{Boolean, "true", "", token.LexLocation}
+		case "false", "no", "off":
+			return HoconToken{Boolean, "false", "", token.LexLocation}
+		}
+	case token.Type == Text && (isIntKind(kind) || isUintKind(kind) || kind == reflect.Float32 || kind == reflect.Float64):
+		tokenType := numberTokenType(token.Value)
+		if tokenType == Float {
+			if _, err := strconv.ParseFloat(token.Value, 64); err != nil {
+				return token
+			}
+		}
+		if tokenType != Text {
+			return HoconToken{tokenType, token.Value, "", token.LexLocation}
+		}
+	case (token.Type == Integer || token.Type == Float || token.Type == Boolean) && kind == reflect.String:
+		return HoconToken{Text, token.Value, "", token.LexLocation}
+	case (token.Type == Duration || token.Type == Size || token.Type == TimePeriod) && kind == reflect.String:
+		// Values with units are strings in HOCON, which are decoded as they were written
+		return HoconToken{Text, token.text(), "", token.LexLocation}
+	}
+	return token
+}
+
+// setNumber sets a Number from a number or a size, or from a string holding a number unless types are strict
+func (parser *HoconParser) setNumber(v reflect.Value, token HoconToken, path string) error {
+	if token.Type == Text && !parser.StrictTypes {
+		token = convertToken(token, reflect.Float64)
+	}
+	if token.Type != Integer && token.Type != Float && token.Type != Size {
+		return &ParserTypeMismatchErr{path, v.Type().String(), tokenValueType(token.Type), token.LexLocation}
+	}
+	v.SetString(token.Value)
+	return nil
+}
+
+// setDuration sets a time.Duration from a number of milliseconds or a string in the duration format
+func (parser *HoconParser) setDuration(v reflect.Value, token HoconToken) error {
+	d, err := ParseDuration(token.Value)
+	if err != nil {
+		return &ParserValueConversionErr{token, durationType.String(), err}
+	}
+	v.SetInt(int64(d))
+	return nil
+}
+
+// setPeriod sets a Period from a period, a number of days or a string in the period format.
+// A duration such as 2m or 3 days is parsed as it was written, so that m is taken to be months
+func (parser *HoconParser) setPeriod(v reflect.Value, token HoconToken) error {
+	var p Period
 	var err error
-	var stack []rune
 
-	for _, token := range tokens {
-		switch token.Type {
-		case LeftBrace, LeftBracket, LeftParen:
-			// Push it on the stack
-			r, size := utf8.DecodeRuneInString(token.Value)
-			if r == utf8.RuneError && (size == 0 || size == 1) {
-				// Return error
-				return &ParserInvalidRuneErr{}
+	switch token.Type {
+	case Duration:
+		if p, err = ParsePeriod(token.text()); err != nil {
+			return &ParserValueConversionErr{token, periodType.String(), err}
+		}
+	case Integer, TimePeriod, Text:
+		if p, err = ParsePeriod(token.Value); err != nil {
+			return withLocation(err, token.LexLocation)
+		}
+	default:
+		return &ParserValueConversionErr{token, periodType.String(), nil}
+	}
+	v.Set(reflect.ValueOf(p))
+	return err
+}
+
+// setTime sets a time.Time from an RFC 3339 timestamp such as 2006-01-02T15:04:05Z or a date such as 2006-01-02
+func (parser *HoconParser) setTime(v reflect.Value, token HoconToken) error {
+	if token.Type == Text || token.Type == Integer {
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, token.Value); err == nil {
+				v.Set(reflect.ValueOf(t))
+				return nil
 			}
-			stack = append(stack, r)
-		case RightBrace, RightBracket, RightParen:
-			// Pop it from the stack
-			l := len(stack)
-			stack = stack[:l-1]
 		}
 	}
-	if len(stack) != 0 {
-		err = &ParserUnbalancedParenthesesErr{}
+	return &ParserValueConversionErr{token, timeType.String(), nil}
+}
+
+func isIntKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
 	}
-	return err
+	return false
+}
+
+func isUintKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
 }
 
 func init() {
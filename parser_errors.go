@@ -1,49 +1,369 @@
 package aconf
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// The categories of errors returned by the lexer and the parser, to be checked using errors.Is
+var (
+	// ErrSyntax is matched by the errors for input which is not valid HOCON
+	ErrSyntax = errors.New("syntax error")
+	// ErrTypeMismatch is matched by the errors for values which cannot be decoded into the type of their target
+	ErrTypeMismatch = errors.New("type mismatch")
+	// ErrMissingRequired is matched by the errors for required values which are not set
+	ErrMissingRequired = errors.New("missing required value")
+	// ErrUnresolvedSubstitution is matched by the errors for ${} substitutions which cannot be resolved
+	ErrUnresolvedSubstitution = errors.New("unresolved substitution")
+	// ErrValidation is matched by the errors for values which fail the constraints of a validate tag or whose
+	// Validate method returns an error
+	ErrValidation = errors.New("validation failed")
+	// ErrIncludeNotFound is matched by the errors for required includes of files which do not exist
+	ErrIncludeNotFound = errors.New("include not found")
+	// ErrUnknownKey is matched by the errors for keys which have no field to decode into, when unknown keys are
+	// disallowed
+	ErrUnknownKey = errors.New("unknown key")
+)
 
 type ParserUnbalancedParenthesesErr struct {
 	LexLocation
 }
 
 func (err *ParserUnbalancedParenthesesErr) Error() string {
-	return fmt.Sprintf("Unbalanced Parentheses")
+	return err.errorf("Unbalanced Parentheses")
+}
+
+func (err *ParserUnbalancedParenthesesErr) Is(target error) bool {
+	return target == ErrSyntax
 }
 
 type ParserInvalidRuneErr struct {
+	LexLocation
 }
 
 func (err *ParserInvalidRuneErr) Error() string {
-	return fmt.Sprintf("Invalid Rune")
+	return err.errorf("Invalid Rune")
+}
+
+func (err *ParserInvalidRuneErr) Is(target error) bool {
+	return target == ErrSyntax
 }
 
 type ParserInvalidArrayErr struct {
-	tokenValue string
+	TokenValue string
 	LexLocation
 }
 
 func (err *ParserInvalidArrayErr) Error() string {
-	return fmt.Sprintf("parser: %d:%d : invalid array element %s", err.lineNumber, err.columnNumber, err.tokenValue)
+	return err.errorf("parser: invalid array element %s", err.TokenValue)
+}
+
+func (err *ParserInvalidArrayErr) Is(target error) bool {
+	return target == ErrSyntax
 }
 
-type ParserInvalidTargetErr struct{ got, want string }
+type ParserInvalidTargetErr struct{ Got, Want string }
 
 func (err *ParserInvalidTargetErr) Error() string {
-	return fmt.Sprintf("Invalid argument to method Parse. Want : %v, Got : %v", err.want, err.got)
+	return fmt.Sprintf("Invalid argument to method Parse. Want : %v, Got : %v", err.Want, err.Got)
 }
 
 type ParserInvalidTokenTypeErr struct {
-	token HoconToken
+	Token HoconToken
 }
 
 func (err *ParserInvalidTokenTypeErr) Error() string {
-	return fmt.Sprintf("parser: Invalid Token : %v", err.token)
+	return err.Token.errorf("parser: Invalid Token : %v", err.Token.Value)
+}
+
+func (err *ParserInvalidTokenTypeErr) Is(target error) bool {
+	return target == ErrSyntax
+}
+
+func (err *ParserInvalidTokenTypeErr) Location() LexLocation {
+	return err.Token.LexLocation
 }
 
 type ParserInvalidInputFieldErr struct {
-	fldName string
+	FieldName string
+	LexLocation
 }
 
 func (err *ParserInvalidInputFieldErr) Error() string {
-	return fmt.Sprintf("parser: Invalid Field in Input : %v", err.fldName)
+	return err.errorf("parser: Invalid Field in Input : %v", err.FieldName)
+}
+
+type ParserInvalidPathErr struct {
+	Path string
+	LexLocation
+}
+
+func (err *ParserInvalidPathErr) Error() string {
+	return err.errorf("parser: invalid path expression %s", err.Path)
+}
+
+func (err *ParserInvalidPathErr) Is(target error) bool {
+	return target == ErrSyntax
+}
+
+type ParserInvalidIncludeErr struct {
+	Token HoconToken
+}
+
+func (err *ParserInvalidIncludeErr) Error() string {
+	return err.Token.errorf("parser: invalid include statement %v", err.Token.Value)
+}
+
+func (err *ParserInvalidIncludeErr) Is(target error) bool {
+	return target == ErrSyntax
+}
+
+func (err *ParserInvalidIncludeErr) Location() LexLocation {
+	return err.Token.LexLocation
+}
+
+type ParserIncludeErr struct {
+	Name string
+	Err  error
+	LexLocation
+}
+
+func (err *ParserIncludeErr) Error() string {
+	return err.errorf("parser: include %s : %v", err.Name, err.Err)
+}
+
+// Is reports whether the target is ErrIncludeNotFound, for a missing file
+func (err *ParserIncludeErr) Is(target error) bool {
+	return target == ErrIncludeNotFound && errors.Is(err.Err, os.ErrNotExist)
+}
+
+func (err *ParserIncludeErr) Unwrap() error {
+	return err.Err
+}
+
+type PropertiesMalformedEscapeErr struct {
+	LexLocation
+}
+
+func (err *PropertiesMalformedEscapeErr) Error() string {
+	return err.errorf("properties: malformed \\uxxxx escape")
+}
+
+func (err *PropertiesMalformedEscapeErr) Is(target error) bool {
+	return target == ErrSyntax
+}
+
+type ParserValueConversionErr struct {
+	Token HoconToken
+	Want  string
+	Err   error
+}
+
+func (err *ParserValueConversionErr) Error() string {
+	if err.Err != nil {
+		return err.Token.errorf("parser: cannot convert %v to %v : %v", err.Token.Value, err.Want, err.Err)
+	}
+	return err.Token.errorf("parser: cannot convert %v to %v", err.Token.Value, err.Want)
+}
+
+func (err *ParserValueConversionErr) Is(target error) bool {
+	return target == ErrTypeMismatch
+}
+
+func (err *ParserValueConversionErr) Unwrap() error {
+	return err.Err
+}
+
+func (err *ParserValueConversionErr) Location() LexLocation {
+	return err.Token.LexLocation
+}
+
+type ErrInvalidPeriod struct {
+	Value string
+	LexLocation
+}
+
+func (e *ErrInvalidPeriod) Error() string {
+	return e.errorf("Invalid Period : %s", e.Value)
+}
+
+func (e *ErrInvalidPeriod) Is(target error) bool {
+	return target == ErrSyntax
+}
+
+type ParserArrayLengthErr struct {
+	Path string
+	Want int
+	Got  int
+	LexLocation
+}
+
+func (err *ParserArrayLengthErr) Error() string {
+	return err.errorf("parser: array %s has %d elements, want %d", err.Path, err.Got, err.Want)
+}
+
+func (err *ParserArrayLengthErr) Is(target error) bool {
+	return target == ErrTypeMismatch
+}
+
+type ParserTypeMismatchErr struct {
+	Path string
+	Want string
+	Got  ValueType
+	LexLocation
+}
+
+func (err *ParserTypeMismatchErr) Error() string {
+	return err.errorf("parser: cannot decode %s %s into %s", err.Got, err.Path, err.Want)
+}
+
+func (err *ParserTypeMismatchErr) Is(target error) bool {
+	return target == ErrTypeMismatch
+}
+
+type ParserUnmarshalErr struct {
+	Path string
+	Err  error
+	LexLocation
+}
+
+func (err *ParserUnmarshalErr) Error() string {
+	return err.errorf("parser: cannot decode %s : %v", err.Path, err.Err)
+}
+
+func (err *ParserUnmarshalErr) Is(target error) bool {
+	return target == ErrTypeMismatch
+}
+
+func (err *ParserUnmarshalErr) Unwrap() error {
+	return err.Err
+}
+
+type ParserValidationErr struct {
+	Path string
+	Err  error
+	LexLocation
+}
+
+func (err *ParserValidationErr) Error() string {
+	if err.Path == "" {
+		return err.errorf("parser: invalid config : %v", err.Err)
+	}
+	return err.errorf("parser: invalid value for %s : %v", err.Path, err.Err)
+}
+
+func (err *ParserValidationErr) Is(target error) bool {
+	return target == ErrValidation
+}
+
+func (err *ParserValidationErr) Unwrap() error {
+	return err.Err
+}
+
+type ParserMissingKeyErr struct {
+	Path string
+	LexLocation
+}
+
+func (err *ParserMissingKeyErr) Error() string {
+	return err.errorf("parser: missing required key %s", err.Path)
+}
+
+func (err *ParserMissingKeyErr) Is(target error) bool {
+	return target == ErrMissingRequired
+}
+
+type ParserUnknownKeyErr struct {
+	Path string
+	LexLocation
+}
+
+func (err *ParserUnknownKeyErr) Error() string {
+	return err.errorf("parser: unknown key %s", err.Path)
+}
+
+func (err *ParserUnknownKeyErr) Is(target error) bool {
+	return target == ErrUnknownKey
+}
+
+// ErrorList holds all the problems found in a single run of the parser: the syntax errors in the order of their
+// locations, followed by the errors found in decoding in the order in which they were found.
+//
+// It is returned when there is more than one problem. errors.As and errors.Is examine each of the errors in the list,
+// and the list can be retrieved using errors.As to range over the individual errors.
+type ErrorList []error
+
+func (list ErrorList) Error() string {
+	msgs := make([]string, len(list))
+	for i, err := range list {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (list ErrorList) Unwrap() []error {
+	return list
+}
+
+// add appends the error, or each of the errors in an ErrorList, leaving out nil errors
+func (list *ErrorList) add(err error) {
+	if err == nil {
+		return
+	}
+	if errs, ok := err.(ErrorList); ok {
+		*list = append(*list, errs...)
+		return
+	}
+	*list = append(*list, err)
+}
+
+// sortByLocation sorts the errors by their lines and columns, keeping the order of those at the same location.
+// Errors without a location are kept last
+func (list ErrorList) sortByLocation() {
+	sort.SliceStable(list, func(i, j int) bool {
+		a, aok := list[i].(interface{ Location() LexLocation })
+		b, bok := list[j].(interface{ Location() LexLocation })
+		if !aok || !bok {
+			return aok && !bok
+		}
+		la, lb := a.Location(), b.Location()
+		return la.lineNumber < lb.lineNumber || (la.lineNumber == lb.lineNumber && la.columnNumber < lb.columnNumber)
+	})
+}
+
+// dedupe removes the errors of the same type found at the same location as an earlier one, such as an unbalanced
+// parenthesis found both by the syntax checks and in building the tree. Errors without a location are kept
+func (list *ErrorList) dedupe() {
+	type errorKey struct {
+		t reflect.Type
+		LexLocation
+	}
+	seen := make(map[errorKey]bool, len(*list))
+	errs := (*list)[:0]
+	for _, err := range *list {
+		if located, ok := err.(interface{ Location() LexLocation }); ok {
+			key := errorKey{reflect.TypeOf(err), located.Location()}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		errs = append(errs, err)
+	}
+	*list = errs
+}
+
+// err returns nil if the list is empty, the only error if it holds one, and the list itself otherwise
+func (list ErrorList) err() error {
+	switch len(list) {
+	case 0:
+		return nil
+	case 1:
+		return list[0]
+	}
+	return list
 }
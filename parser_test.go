@@ -1,7 +1,16 @@
 package aconf
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -49,7 +58,8 @@ var unbalancedParenTests = []struct {
 	err      error
 }{
 
-	{unbalancedParenContents, &ParserUnbalancedParenthesesErr{}},
+	{unbalancedParenContents, &ParserUnbalancedParenthesesErr{LexLocation{lineNumber: 3, columnNumber: 10}}},
+	{"a {\n}\n}", &ParserUnbalancedParenthesesErr{LexLocation{lineNumber: 3, columnNumber: 1}}},
 }
 
 type TestTableStruct struct {
@@ -317,6 +327,284 @@ func TestArrayOfObjectsInArray(t *testing.T) {
 	t.Logf("After : %v", target)
 }
 
+func TestCommentsInsideBlocks(t *testing.T) {
+	fileContents := `
+	// Leading comment
+	A = 20 // trailing comment
+	B = [ # the first array
+		1, // one
+		2  # two
+	]
+	C {
+		D = some text // not part of the value
+		# Another comment
+		E = [3, 4] // trailing comment after an array
+	}
+	`
+	type TargetStruct struct {
+		A int
+		B []int
+		C struct {
+			D string
+			E []int
+		}
+	}
+	target := &TargetStruct{}
+	parser := &HoconParser{}
+	reader := strings.NewReader(fileContents)
+	if err := parser.Parse(reader, target); err != nil {
+		t.Errorf("failed for input : %v. Error : %v", fileContents, err)
+	}
+	if !(target.A == 20 && len(target.B) == 2 && target.B[1] == 2 && target.C.D == "some text" && len(target.C.E) == 2 && target.C.E[1] == 4) {
+		t.Errorf("input: %v, After : %v", fileContents, target)
+	}
+}
+
+var splitPathTests = []struct {
+	path     string
+	elements []string
+}{
+	{`a`, []string{"a"}},
+	{`a.b.c`, []string{"a", "b", "c"}},
+	{`foo.bar."hello.world"`, []string{"foo", "bar", "hello.world"}},
+	{`"10.0.0.1"`, []string{"10.0.0.1"}},
+	{`a."".b`, []string{"a", "", "b"}},
+	{`"a\"b".c`, []string{`a"b`, "c"}},
+	{`a..b`, nil},
+	{`.a`, nil},
+	{`a.`, nil},
+	{`"a`, nil},
+}
+
+func TestSplitPath(t *testing.T) {
+	for _, test := range splitPathTests {
+		elements, err := splitPath(test.path)
+		if test.elements == nil {
+			if _, ok := err.(*ParserInvalidPathErr); !ok {
+				t.Errorf("path: %v, Expected : %v, Got : %v", test.path, &ParserInvalidPathErr{Path: test.path}, err)
+			}
+			continue
+		}
+		if err != nil || strings.Join(elements, "|") != strings.Join(test.elements, "|") {
+			t.Errorf("path: %v, Expected : %q, Got : %q, %v", test.path, test.elements, elements, err)
+		}
+	}
+}
+
+func TestQuotedKeys(t *testing.T) {
+	fileContents := `
+	"akka.actor" = local
+	hosts {
+		"10.0.0.1" = primary
+		"content-type: json" = "yes"
+	}
+	`
+	type TargetStruct struct {
+		Actor string `hocon:"akka.actor"`
+		Hosts struct {
+			Primary string `hocon:"10.0.0.1"`
+		} `hocon:"hosts"`
+	}
+	target := &TargetStruct{}
+	parser := &HoconParser{}
+	if err := parser.Parse(strings.NewReader(fileContents), target); err != nil {
+		t.Errorf("failed for input : %v. Error : %v", fileContents, err)
+	}
+	if !(target.Actor == "local" && target.Hosts.Primary == "primary") {
+		t.Errorf("input: %v, After : %v", fileContents, target)
+	}
+
+	m := map[string]interface{}{}
+	if err := parser.Parse(strings.NewReader(fileContents), &m); err != nil {
+		t.Errorf("failed for input : %v. Error : %v", fileContents, err)
+	}
+	hosts, ok := m["hosts"].(map[string]interface{})
+	if !(ok && m["akka.actor"] == "local" && hosts["10.0.0.1"] == "primary" && hosts["content-type: json"] == "yes") {
+		t.Errorf("input: %v, After : %v", fileContents, m)
+	}
+}
+
+func TestLineBreaks(t *testing.T) {
+	for _, fileContents := range []string{
+		"a = \"x\"\r\n\"b.c\" = 2",
+		"a = \"x\"\r\n\r\n\"b.c\" = 2\r\n",
+		"a { x = 1 } \n\"b.c\" = 2",
+		"a = \"x\" \t\n\"b.c\" = 2",
+		"a = x // comment\r\n\"b.c\" = 2",
+	} {
+		m := map[string]interface{}{}
+		if err := (&HoconParser{}).Parse(strings.NewReader(fileContents), &m); err != nil {
+			t.Errorf("failed for input : %q. Error : %v", fileContents, err)
+			continue
+		}
+		if !(m["b.c"] == int64(2) && m["a"] != nil) {
+			t.Errorf("input: %q, After : %v", fileContents, m)
+		}
+	}
+}
+
+func TestPathExpressionsAndDuplicateKeys(t *testing.T) {
+	type TargetStruct struct {
+		A struct {
+			B int `hocon:"b"`
+			C map[string]string
+		} `hocon:"a"`
+	}
+	target := &TargetStruct{}
+	parser := &HoconParser{}
+	fileContents := duplicateKeys + `
+	a.C."x.y" = z
+	a { C { w = v } }`
+	if err := parser.Parse(strings.NewReader(fileContents), target); err != nil {
+		t.Errorf("failed for input : %v. Error : %v", fileContents, err)
+	}
+	if !(target.A.B == 20 && target.A.C["x.y"] == "z" && target.A.C["w"] == "v") {
+		t.Errorf("input: %v, After : %v", fileContents, target)
+	}
+}
+
+func TestNumericallyIndexedObjects(t *testing.T) {
+	fileContents := `
+	List { "0" : a, "1" : b }
+	Sparse.2 = d
+	Sparse.0 = c
+	Sparse.name = ignored
+	Servers = [
+		{ Host = "a", Port = 1 }
+		{ Host = "b", Port = 2 }
+	]
+	Servers.0.Host = x
+	Servers.2.Host = c
+	Ports = [10, 20]
+	Ports.1 = 30
+	`
+	type Server struct {
+		Host string
+		Port int
+	}
+	type TargetStruct struct {
+		List    []string
+		Sparse  []string
+		Servers []Server
+		Ports   []int
+		Empty   []int
+	}
+	target := &TargetStruct{}
+	parser := &HoconParser{}
+	if err := parser.Parse(strings.NewReader(fileContents), target); err != nil {
+		t.Errorf("failed for input : %v. Error : %v", fileContents, err)
+	}
+	want := TargetStruct{
+		List:    []string{"a", "b"},
+		Sparse:  []string{"c", "d"},
+		Servers: []Server{{"x", 1}, {"b", 2}, {"c", 0}},
+		Ports:   []int{10, 30},
+	}
+	if !reflect.DeepEqual(*target, want) {
+		t.Errorf("Got: %v, Want : %v", *target, want)
+	}
+
+	// Objects without numeric keys, including empty objects, are not converted
+	for _, contents := range []string{"Empty {}", "Empty { a = 1 }"} {
+		if err := parser.Parse(strings.NewReader(contents), target); !errors.Is(err, ErrTypeMismatch) {
+			t.Errorf("input: %v, Expected : %v, Got : %v", contents, ErrTypeMismatch, err)
+		}
+	}
+}
+
+func TestFractionalAndCompoundDurations(t *testing.T) {
+	fileContents := `
+	A = 1.5 hours
+	B = 0.5s
+	C = 1h30m15s
+	D = 250
+	E = "2.5 days"
+	`
+	type TargetStruct struct {
+		A, B, C, D, E time.Duration
+	}
+	target := &TargetStruct{}
+	parser := &HoconParser{}
+	if err := parser.Parse(strings.NewReader(fileContents), target); err != nil {
+		t.Fatalf("failed for input : %v. Error : %v", fileContents, err)
+	}
+	want := TargetStruct{90 * time.Minute, 500 * time.Millisecond, time.Hour + 30*time.Minute + 15*time.Second, 250 * time.Millisecond, 60 * time.Hour}
+	if *target != want {
+		t.Errorf("Got: %v, Want : %v", *target, want)
+	}
+
+	err := parser.Parse(strings.NewReader(`A = 10 fortnights`), &TargetStruct{})
+	if e, ok := err.(*ParserValueConversionErr); !ok {
+		t.Errorf("Expected : *ParserValueConversionErr, Got : %v", err)
+	} else if _, ok := e.Err.(*ErrInvalidDurationUnit); !ok {
+		t.Errorf("Expected : *ErrInvalidDurationUnit, Got : %v", e.Err)
+	}
+}
+
+func TestPeriodsAndTimes(t *testing.T) {
+	fileContents := `
+	Days = 3 days
+	Weeks = 2 weeks
+	Months = 2 months
+	Year = 1 year
+	Bare = 5
+	Quoted = "2 mo"
+	Retention = 3 days
+	Start = "2024-01-02T03:04:05.5+01:00"
+	Date = "2024-01-02"
+	M = 2m
+	N = 2 m
+	`
+	type TargetStruct struct {
+		Days, Weeks, Months, Year, Bare, Quoted, M, N Period
+		Retention                                     time.Duration
+		Start, Date                                   time.Time
+	}
+	target := &TargetStruct{}
+	parser := &HoconParser{}
+	if err := parser.Parse(strings.NewReader(fileContents), target); err != nil {
+		t.Fatalf("failed for input : %v. Error : %v", fileContents, err)
+	}
+	want := TargetStruct{
+		Days:      Period{Days: 3},
+		Weeks:     Period{Days: 14},
+		Months:    Period{Months: 2},
+		Year:      Period{Years: 1},
+		Bare:      Period{Days: 5},
+		Quoted:    Period{Months: 2},
+		Retention: 3 * 24 * time.Hour,
+		Start:     time.Date(2024, 1, 2, 2, 4, 5, 500000000, time.UTC),
+		Date:      time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		M:         Period{Months: 2},
+		N:         Period{Months: 2},
+	}
+	if !(target.Days == want.Days && target.Weeks == want.Weeks && target.Months == want.Months && target.Year == want.Year &&
+		target.Bare == want.Bare && target.Quoted == want.Quoted && target.Retention == want.Retention &&
+		target.Start.Equal(want.Start) && target.Date.Equal(want.Date) && target.M == want.M && target.N == want.N) {
+		t.Errorf("Got: %+v, Want : %+v", *target, want)
+	}
+
+	for _, contents := range []string{`Retention = 2 months`, `Start = "yesterday"`, `Days = 10 seconds`} {
+		err := parser.Parse(strings.NewReader(contents), &TargetStruct{})
+		if _, ok := err.(*ParserValueConversionErr); !ok {
+			t.Errorf("input: %v, Expected : *ParserValueConversionErr, Got : %v", contents, err)
+		}
+	}
+}
+
+func TestUnBalancedParenthesesFromFile(t *testing.T) {
+	reader, err := os.Open("test_data/hocon.unbalanced.paren.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	parser := &HoconParser{}
+	want := &ParserUnbalancedParenthesesErr{LexLocation{fileName: "test_data/hocon.unbalanced.paren.conf", lineNumber: 2, columnNumber: 9}}
+	if err := parser.Parse(reader, nil); err == nil || !errorsAreEqual(err, want) {
+		t.Errorf("Expected : %v, Got : %v", want, err)
+	}
+}
+
 func TestUnBalancedParentheses(t *testing.T) {
 	for _, test := range unbalancedParenTests {
 		parser := &HoconParser{}
@@ -327,6 +615,478 @@ func TestUnBalancedParentheses(t *testing.T) {
 	}
 }
 
+func TestErrorMessageLocations(t *testing.T) {
+	type TargetStruct struct {
+		Start time.Time
+	}
+	tests := []struct {
+		contents string
+		want     string
+	}{
+		{`Start = @now`, "app.conf:1:9: lexer: Invalid Token @"},
+		{"a {\n  b = [1, 2\n}", "app.conf:1:3: Unbalanced Parentheses"},
+		{"a = 1\ninclude required(\"test_data/missing.conf\")", "app.conf:2:1: parser: include test_data/missing.conf"},
+		{`Start = "yesterday"`, "app.conf:1:9: parser: cannot convert yesterday to time.Time"},
+	}
+	for _, test := range tests {
+		parser := &HoconParser{}
+		err := parser.Parse(&namedReader{strings.NewReader(test.contents), "app.conf"}, &TargetStruct{})
+		if err == nil || !strings.HasPrefix(err.Error(), test.want) {
+			t.Errorf("input: %q, Expected : %v, Got : %v", test.contents, test.want, err)
+		}
+	}
+}
+
+func TestAllErrorsReported(t *testing.T) {
+	type TargetStruct struct {
+		Name    string        `hocon:"name"`
+		Port    int           `hocon:"port"`
+		Timeout time.Duration `hocon:"timeout"`
+		Start   time.Time     `hocon:"start"`
+		Retries int           `hocon:"retries"`
+		Hosts   []string      `hocon:"hosts"`
+	}
+	contents := `
+	name = "unterminated
+	port = @8080
+	timeout = 10 fortnights
+	start = yesterday
+	retries = 3
+	unknown = 1
+	hosts = [a, b
+	`
+	target := &TargetStruct{}
+	parser := &HoconParser{DisallowUnknownKeys: true}
+	err := parser.Parse(strings.NewReader(contents), target)
+
+	var list ErrorList
+	if !errors.As(err, &list) || len(list) != 6 {
+		t.Fatalf("Expected : 6 errors, Got : %v", err)
+	}
+	var scannerErr *LexScannerErr
+	var tokenErr *ErrLexerInvalidToken
+	var conversionErr *ParserValueConversionErr
+	var unknownErr *ParserUnknownKeyErr
+	var unbalancedErr *ParserUnbalancedParenthesesErr
+	if !errors.As(err, &scannerErr) || scannerErr.Line() != 2 {
+		t.Errorf("Expected : *LexScannerErr on line 2, Got : %v", err)
+	}
+	if !errors.As(err, &tokenErr) || tokenErr.Line() != 3 {
+		t.Errorf("Expected : *ErrLexerInvalidToken on line 3, Got : %v", err)
+	}
+	if !errors.As(err, &unknownErr) || unknownErr.Path != "unknown" || unknownErr.Line() != 7 {
+		t.Errorf("Expected : *ParserUnknownKeyErr on line 7, Got : %v", err)
+	}
+	if !errors.As(err, &unbalancedErr) || unbalancedErr.Line() != 8 {
+		t.Errorf("Expected : *ParserUnbalancedParenthesesErr on line 8, Got : %v", err)
+	}
+	conversions := 0
+	for _, e := range list {
+		if errors.As(e, &conversionErr) {
+			conversions++
+		}
+	}
+	if conversions != 2 {
+		t.Errorf("Expected : 2 *ParserValueConversionErr, Got : %v", err)
+	}
+	if target.Retries != 3 {
+		t.Errorf("Got: %v, Want : %v", target.Retries, 3)
+	}
+}
+
+func TestManyErrorsReported(t *testing.T) {
+	var contents strings.Builder
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&contents, "key%d = @\nunknown%d = 1\n", i, i)
+	}
+	var target struct{ Name string }
+	err := (&HoconParser{DisallowUnknownKeys: true}).Parse(strings.NewReader(contents.String()), &target)
+
+	var list ErrorList
+	if !errors.As(err, &list) || len(list) != 10000 {
+		t.Fatalf("Expected : 10000 errors, Got : %d", len(list))
+	}
+}
+
+func TestErrorCategories(t *testing.T) {
+	type TargetStruct struct {
+		Start time.Time
+	}
+	tests := []struct {
+		contents string
+		want     error
+	}{
+		{`Start = @now`, ErrSyntax},
+		{"a {\n  b = [1, 2\n}", ErrSyntax},
+		{`Start = ${now}`, ErrUnresolvedSubstitution},
+		{`include required("test_data/missing.conf")`, ErrIncludeNotFound},
+		{`include required("test_data/missing.conf")`, os.ErrNotExist},
+		{`Start = yesterday`, ErrTypeMismatch},
+	}
+	for _, test := range tests {
+		parser := &HoconParser{}
+		err := parser.Parse(strings.NewReader(test.contents), &TargetStruct{})
+		if !errors.Is(err, test.want) {
+			t.Errorf("input: %q, Expected : %v, Got : %v", test.contents, test.want, err)
+		}
+		if test.want != ErrSyntax && errors.Is(err, ErrSyntax) {
+			t.Errorf("input: %q, Expected : not %v, Got : %v", test.contents, ErrSyntax, err)
+		}
+	}
+
+	converting := &HoconParser{}
+	converting.RegisterConverter(StringValue, reflect.TypeOf(time.Time{}), func(value interface{}) (interface{}, error) {
+		return nil, errors.New("not a time")
+	})
+	decodeTests := []struct {
+		parser   *HoconParser
+		contents string
+		target   interface{}
+		want     error
+	}{
+		{&HoconParser{DisallowUnknownKeys: true}, `Stop = yesterday`, &TargetStruct{}, ErrUnknownKey},
+		{converting, `Start = yesterday`, &TargetStruct{}, ErrTypeMismatch},
+		{&HoconParser{}, `Start { at = yesterday }`, &struct{ Start flexibleValue }{}, ErrTypeMismatch},
+	}
+	for _, test := range decodeTests {
+		err := test.parser.Parse(strings.NewReader(test.contents), test.target)
+		if !errors.Is(err, test.want) {
+			t.Errorf("input: %q, Expected : %v, Got : %v", test.contents, test.want, err)
+		}
+	}
+
+	parser := &HoconParser{}
+	err := parser.Parse(strings.NewReader(`Start = yesterday`), &TargetStruct{})
+	var conversionErr *ParserValueConversionErr
+	if !errors.As(err, &conversionErr) || conversionErr.Token.Value != "yesterday" || conversionErr.Want != "time.Time" {
+		t.Errorf("Expected : %v, Got : %v", &ParserValueConversionErr{Token: HoconToken{Text, "yesterday", "", LexLocation{}}, Want: "time.Time"}, err)
+	}
+}
+
+func TestAutomaticTypeConversions(t *testing.T) {
+	type TargetStruct struct {
+		Port    int     `hocon:"port"`
+		Workers uint8   `hocon:"workers"`
+		Ratio   float64 `hocon:"ratio"`
+		Enabled bool    `hocon:"enabled"`
+		Debug   bool    `hocon:"debug"`
+		Version string  `hocon:"version"`
+		Verbose string  `hocon:"verbose"`
+	}
+	contents := `
+	port = "8080"
+	workers = "4"
+	ratio = "0.75"
+	enabled = yes
+	debug = "off"
+	version = 1.5
+	verbose = true
+	`
+	want := &TargetStruct{8080, 4, 0.75, true, false, "1.5", "true"}
+	target := &TargetStruct{}
+	parser := &HoconParser{}
+	if err := parser.Parse(strings.NewReader(contents), target); err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+	if !reflect.DeepEqual(target, want) {
+		t.Errorf("Got: %+v, Want : %+v", target, want)
+	}
+
+	// Only the six spec booleans and valid numbers convert
+	for _, contents := range []string{`enabled = maybe`, `port = "80 80"`, `ratio = "1.2.3"`, `workers = "-1"`} {
+		err := parser.Parse(strings.NewReader(contents), &TargetStruct{})
+		if !errors.Is(err, ErrTypeMismatch) {
+			t.Errorf("input: %q, Expected : %v, Got : %v", contents, ErrTypeMismatch, err)
+		}
+	}
+
+	strict := &HoconParser{StrictTypes: true}
+	err := strict.Parse(strings.NewReader(contents), &TargetStruct{})
+	var list ErrorList
+	if !errors.As(err, &list) || len(list) != 7 || !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected : 7 type mismatches, Got : %v", err)
+	}
+}
+
+func TestValuesWithUnitsAsStrings(t *testing.T) {
+	var target struct {
+		S string
+		L []string
+	}
+	parser := &HoconParser{}
+	for _, value := range []string{"10 seconds", "5m", "1.5h", "5 GB", "512kB", "1y", "2 months"} {
+		if err := parser.Parse(strings.NewReader("S = "+value), &target); err != nil || target.S != value {
+			t.Errorf("input: %q, Got: %q, %v", value, target.S, err)
+		}
+	}
+	if err := parser.Parse(strings.NewReader("L = [10s, 1KB, 3w, 2y, abc]"), &target); err != nil || !reflect.DeepEqual(target.L, []string{"10s", "1KB", "3w", "2y", "abc"}) {
+		t.Errorf("Got: %q, %v", target.L, err)
+	}
+}
+
+func TestNestedSequences(t *testing.T) {
+	type Server struct {
+		Host  string    `hocon:"host"`
+		Ports []int     `hocon:"ports"`
+		Tags  [2]string `hocon:"tags"`
+	}
+	type TargetStruct struct {
+		Matrix  [][]int     `hocon:"matrix"`
+		Cube    [][][]int   `hocon:"cube"`
+		Fixed   [3]string   `hocon:"fixed"`
+		Grid    [2][2]int   `hocon:"grid"`
+		Servers []Server    `hocon:"servers"`
+		Groups  [][]Server  `hocon:"groups"`
+		Indexed [2]string   `hocon:"indexed"`
+		Any     interface{} `hocon:"any"`
+	}
+	contents := `
+	matrix = [[1, 2], [3]]
+	cube = [[[1], [2, 3]], []]
+	fixed = [a, b, c]
+	grid = [
+		[1, 2]
+		[3, 4]
+	]
+	servers = [{ host = x, ports = [1, 2], tags = [p, q] }, { host = y, ports = [] }]
+	groups = [[{ host = z, ports = [3] }]]
+	indexed { "1" : b, "0" : a }
+	any = [[1, a], { b = [true] }]
+	`
+	target := &TargetStruct{}
+	parser := &HoconParser{}
+	if err := parser.Parse(strings.NewReader(contents), target); err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+	want := &TargetStruct{
+		Matrix:  [][]int{{1, 2}, {3}},
+		Cube:    [][][]int{{{1}, {2, 3}}, {}},
+		Fixed:   [3]string{"a", "b", "c"},
+		Grid:    [2][2]int{{1, 2}, {3, 4}},
+		Servers: []Server{{"x", []int{1, 2}, [2]string{"p", "q"}}, {"y", []int{}, [2]string{}}},
+		Groups:  [][]Server{{{"z", []int{3}, [2]string{}}}},
+		Indexed: [2]string{"a", "b"},
+		Any:     []interface{}{[]interface{}{int64(1), "a"}, map[string]interface{}{"b": []interface{}{true}}},
+	}
+	if !reflect.DeepEqual(target, want) {
+		t.Errorf("Got: %+v, Want : %+v", target, want)
+	}
+
+	lengthTests := []struct {
+		contents string
+		path     string
+	}{
+		{`fixed = [a, b]`, "fixed"},
+		{`fixed = [a, b, c, d]`, "fixed"},
+		{`servers = [{ tags = [p] }]`, "servers[0].tags"},
+	}
+	for _, test := range lengthTests {
+		target := &TargetStruct{}
+		err := parser.Parse(strings.NewReader(test.contents), target)
+		var lengthErr *ParserArrayLengthErr
+		if !errors.As(err, &lengthErr) || !errors.Is(err, ErrTypeMismatch) || lengthErr.Path != test.path {
+			t.Errorf("input: %v, Expected : *ParserArrayLengthErr for %v, Got : %v", test.contents, test.path, err)
+		}
+		if target.Fixed[0] != "" && target.Fixed[1] != "b" {
+			t.Errorf("input: %v, Got: %v, Want : the elements which fit", test.contents, target.Fixed)
+		}
+	}
+}
+
+// flexibleValue accepts any value which is not an object, by implementing Unmarshaler
+type flexibleValue string
+
+func (f *flexibleValue) UnmarshalHOCON(value interface{}) error {
+	if _, ok := value.(map[string]interface{}); ok {
+		return errors.New("objects are not accepted")
+	}
+	*f = flexibleValue(fmt.Sprint(value))
+	return nil
+}
+
+func TestArrayElementTypes(t *testing.T) {
+	type Server struct {
+		Port int `hocon:"port"`
+	}
+	type TargetStruct struct {
+		Ports    []int           `hocon:"ports"`
+		Servers  []Server        `hocon:"servers"`
+		Mixed    []interface{}   `hocon:"mixed"`
+		Flexible []flexibleValue `hocon:"flexible"`
+		Sizes    []uint          `hocon:"sizes"`
+	}
+	contents := `
+	ports = [1, 2, x, 4, 5s]
+	servers = [{ port = 1 }, { port = 2 }, { port = 3 }, { port = abc }, [5]]
+	mixed = [1, a, true, { x = 1 }, [2]]
+	flexible = [1, a, { b = 2 }, 10s]
+	sizes = [1KB, -1]
+	`
+	target := &TargetStruct{}
+	parser := &HoconParser{}
+	err := parser.Parse(strings.NewReader(contents), target)
+
+	var list ErrorList
+	if !errors.As(err, &list) {
+		t.Fatalf("Expected : ErrorList, Got : %v", err)
+	}
+	var paths []string
+	for _, e := range list {
+		var mismatchErr *ParserTypeMismatchErr
+		var unmarshalErr *ParserUnmarshalErr
+		var conversionErr *ParserValueConversionErr
+		switch {
+		case errors.As(e, &mismatchErr):
+			paths = append(paths, mismatchErr.Path)
+		case errors.As(e, &unmarshalErr):
+			paths = append(paths, unmarshalErr.Path)
+		case errors.As(e, &conversionErr):
+			paths = append(paths, conversionErr.Token.Value)
+		}
+	}
+	wantPaths := []string{"ports[2]", "ports[4]", "servers[3].port", "servers[4]", "flexible[2]", "-1"}
+	if !reflect.DeepEqual(paths, wantPaths) {
+		t.Errorf("Got: %v, Want : %v", paths, wantPaths)
+	}
+
+	want := &TargetStruct{
+		Ports:    []int{1, 2, 0, 4, 0},
+		Servers:  []Server{{1}, {2}, {3}, {0}, {0}},
+		Mixed:    []interface{}{int64(1), "a", true, map[string]interface{}{"x": int64(1)}, []interface{}{int64(2)}},
+		Flexible: []flexibleValue{"1", "a", "", "10s"},
+		Sizes:    []uint{1024, 0},
+	}
+	if !reflect.DeepEqual(target, want) {
+		t.Errorf("Got: %+v, Want : %+v", target, want)
+	}
+}
+
+func TestConcurrentParse(t *testing.T) {
+	good := []byte(`
+	name = "axlrate-imdg"
+	axlrate {
+		timeout = 10 seconds
+		hosts = [a, b, c]
+		imdg.size = 5 GB
+		servers = [{ host = x, port = 1 }, { host = y, port = 2 }]
+	}
+	axlrate.servers.1.port = 3
+	`)
+	parser := &HoconParser{IncludeResolver: func(name string) (io.ReadCloser, error) {
+		if name != "base.conf" {
+			return nil, os.ErrNotExist
+		}
+		return ioutil.NopCloser(strings.NewReader("a { x = 1 }")), nil
+	}}
+	want := &map[string]interface{}{}
+	if err := parser.Parse(bytes.NewReader(good), want); err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				got := &map[string]interface{}{}
+				if err := parser.Parse(bytes.NewReader(good), got); err != nil || !reflect.DeepEqual(got, want) {
+					t.Errorf("Got: %v %v, Want : %v", got, err, want)
+				}
+				included := map[string]interface{}{}
+				if err := parser.Parse(strings.NewReader(`include "base.conf"`+"\na.y = 2"), &included); err != nil || len(included["a"].(map[string]interface{})) != 2 {
+					t.Errorf("Got: %v %v", included, err)
+				}
+				if err := parser.Parse(strings.NewReader("a = @\nb = [1"), &map[string]interface{}{}); err == nil {
+					t.Errorf("Expected : errors, Got : nil")
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+type embeddedTarget struct {
+	X int
+}
+
+type EmbeddedTarget struct {
+	Y int
+}
+
+func TestMalformedInputDoesNotPanic(t *testing.T) {
+	inputs := []string{"", "}", "]", "{", "[", ")", "=", "a = }", "[1]", "}\n}", "a = [", `"`, `a."`, `"a" = `, "a = [1, }", "include", "include required(", "a { b = [ } ]", "\x00\xff", "a.0.1 = 2", "a = \"\\u12\""}
+	for _, contents := range inputs {
+		for _, target := range []interface{}{&map[string]interface{}{}, &fuzzTarget{}, &[]int{}} {
+			parser := &HoconParser{}
+			parser.Parse(strings.NewReader(contents), target)
+			parser.ParseProperties(strings.NewReader(contents), target)
+		}
+		l, _ := NewLexer(strings.NewReader(contents))
+		l.Run()
+	}
+
+	// Promoted fields of nil embedded pointers are allocated if exported, and skipped otherwise
+	target := &struct {
+		*embeddedTarget
+		*EmbeddedTarget
+	}{}
+	parser := &HoconParser{}
+	if err := parser.Parse(strings.NewReader("X = 1\nY = 2"), target); err != nil {
+		t.Errorf("Expected : Nil, Got : %v", err)
+	}
+	if target.embeddedTarget != nil || target.EmbeddedTarget == nil || target.Y != 2 {
+		t.Errorf("Got: %+v", target)
+	}
+}
+
+// seedCorpus adds the contents of the files in test_data to the corpus of a fuzz target
+func seedCorpus(f *testing.F) {
+	files, err := filepath.Glob("test_data/*")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, file := range files {
+		contents, err := ioutil.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(contents)
+	}
+}
+
+// fuzzTarget has fields of each of the types which can be decoded into
+type fuzzTarget struct {
+	A, B, C  int
+	X, Y     float64
+	S, Name  string
+	T        time.Duration
+	P        Period
+	Start    time.Time
+	Ok       bool
+	Hosts    []string
+	Ports    []int
+	Nested   *fuzzTarget
+	Children []fuzzTarget
+	Values   map[string]interface{}
+	Any      interface{}
+}
+
+func FuzzParse(f *testing.F) {
+	seedCorpus(f)
+	f.Add([]byte(unbalancedParenContents))
+	f.Add([]byte("}"))
+	f.Add([]byte("[1, 2]"))
+	f.Fuzz(func(t *testing.T, contents []byte) {
+		parser := &HoconParser{IncludeResolver: func(name string) (io.ReadCloser, error) {
+			return nil, os.ErrNotExist
+		}}
+		parser.Parse(bytes.NewReader(contents), &map[string]interface{}{})
+		parser.Parse(bytes.NewReader(contents), &fuzzTarget{})
+		parser.ParseProperties(bytes.NewReader(contents), &map[string]interface{}{})
+	})
+}
+
 func errorsAreEqual(actual, expected error) bool {
 	ok := false
 	if actual == nil && expected == nil {
@@ -335,9 +1095,27 @@ func errorsAreEqual(actual, expected error) bool {
 	switch actual := actual.(type) {
 	case *ParserUnbalancedParenthesesErr:
 		e, matched := expected.(*ParserUnbalancedParenthesesErr)
-		ok = matched && (actual.columnNumber == e.columnNumber) && actual.lineNumber == e.lineNumber
+		ok = matched && (actual.columnNumber == e.columnNumber) && actual.lineNumber == e.lineNumber && actual.fileName == e.fileName
 	case nil:
 		ok = true
 	}
 	return ok
 }
+
+func TestSignedIntegerKinds(t *testing.T) {
+	type TargetStruct struct {
+		A int
+		B int8
+		C int16
+		D int32
+		E int64
+	}
+	target := &TargetStruct{}
+	parser := &HoconParser{}
+	if err := parser.Parse(strings.NewReader("A = 1\nB = 2\nC = 3\nD = 4\nE = 5"), target); err != nil {
+		t.Errorf("failed with non-nil error : %v", err)
+	}
+	if *target != (TargetStruct{1, 2, 3, 4, 5}) {
+		t.Errorf("Got: %+v, Want : %+v", *target, TargetStruct{1, 2, 3, 4, 5})
+	}
+}
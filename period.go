@@ -0,0 +1,84 @@
+package aconf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Period is an amount of calendar time in years, months and days, such as 3 days, 2 months or 1 year.
+//
+// Unlike a time.Duration, the length of a Period depends on the date it is added to.
+type Period struct {
+	Years  int
+	Months int
+	Days   int
+}
+
+// ParsePeriod parses a value in the HOCON period format, i.e. a number followed by an optional unit.
+// Bare numbers are taken to be days. The supported units are
+//
+//	d, day, days
+//	w, week, weeks
+//	m, mo, month, months
+//	y, year, years
+func ParsePeriod(s string) (Period, error) {
+	var p Period
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) && r != '-' && r != '+' })
+	if i < 0 {
+		i = len(s)
+	}
+	n, err := strconv.Atoi(s[:i])
+	if err != nil {
+		return p, &ErrInvalidPeriod{Value: s}
+	}
+	switch strings.TrimSpace(s[i:]) {
+	case "", "d", "day", "days":
+		p.Days = n
+	case "w", "week", "weeks":
+		p.Days = 7 * n
+	case "m", "mo", "month", "months":
+		p.Months = n
+	case "y", "year", "years":
+		p.Years = n
+	default:
+		return p, &ErrInvalidPeriod{Value: s}
+	}
+	return p, nil
+}
+
+// AddTo returns the time t plus the period
+func (p Period) AddTo(t time.Time) time.Time {
+	return t.AddDate(p.Years, p.Months, p.Days)
+}
+
+// Duration returns the period as a time.Duration of 24 hour days.
+// This is only possible if the period has no years or months, whose length varies.
+func (p Period) Duration() (time.Duration, bool) {
+	if p.Years != 0 || p.Months != 0 {
+		return 0, false
+	}
+	return time.Duration(p.Days) * 24 * time.Hour, true
+}
+
+// String returns the period in the ISO-8601 format, e.g. P1Y2M3D
+func (p Period) String() string {
+	if p == (Period{}) {
+		return "P0D"
+	}
+	var b strings.Builder
+	b.WriteString("P")
+	if p.Years != 0 {
+		fmt.Fprintf(&b, "%dY", p.Years)
+	}
+	if p.Months != 0 {
+		fmt.Fprintf(&b, "%dM", p.Months)
+	}
+	if p.Days != 0 {
+		fmt.Fprintf(&b, "%dD", p.Days)
+	}
+	return b.String()
+}
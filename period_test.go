@@ -0,0 +1,48 @@
+package aconf
+
+import (
+	"testing"
+	"time"
+)
+
+var parsePeriodTests = []struct {
+	value  string
+	period Period
+	iso    string
+}{
+	{"3", Period{Days: 3}, "P3D"},
+	{"3 days", Period{Days: 3}, "P3D"},
+	{"1d", Period{Days: 1}, "P1D"},
+	{"2 weeks", Period{Days: 14}, "P14D"},
+	{"2 m", Period{Months: 2}, "P2M"},
+	{"2mo", Period{Months: 2}, "P2M"},
+	{"1 year", Period{Years: 1}, "P1Y"},
+	{"0 years", Period{}, "P0D"},
+}
+
+func TestParsePeriod(t *testing.T) {
+	for _, test := range parsePeriodTests {
+		p, err := ParsePeriod(test.value)
+		if err != nil || p != test.period || p.String() != test.iso {
+			t.Errorf("input: %v, Got: %v (%v), Want: %v, err = %v", test.value, p, p.String(), test.iso, err)
+		}
+	}
+	for _, value := range []string{"", "days", "3 fortnights", "1.5 days", "3 Days"} {
+		if _, err := ParsePeriod(value); err == nil {
+			t.Errorf("input: %v, Expected : %v, Got : nil", value, &ErrInvalidPeriod{Value: value})
+		}
+	}
+}
+
+func TestPeriodArithmetic(t *testing.T) {
+	start := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	if got := (Period{Months: 1}).AddTo(start); !got.Equal(time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Got: %v", got)
+	}
+	if d, ok := (Period{Days: 2}).Duration(); !ok || d != 48*time.Hour {
+		t.Errorf("Got: %v, %v", d, ok)
+	}
+	if _, ok := (Period{Years: 1}).Duration(); ok {
+		t.Errorf("Expected a period with years to have no fixed duration")
+	}
+}
@@ -0,0 +1,138 @@
+package aconf
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// parseProperties reads Java properties from the reader and builds the equivalent tree of objects.
+//
+// Each key is split on '.' into path elements, keeping any empty elements, and values are always strings.
+// Where a key is both a value and an object, e.g. a=hello and a.b=world, the object wins.
+func parseProperties(reader io.Reader) (*hoconNode, error) {
+	fileName := readerName(reader)
+	root := newObjectNode(LexLocation{fileName: fileName, lineNumber: 1, columnNumber: 1})
+	bufReader := bufio.NewReader(reader)
+	lineNumber, offset := 0, 0
+	for {
+		line, err := bufReader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		lineNumber++
+		indent := len(line) - len(strings.TrimLeft(line, " \t\f"))
+		location := LexLocation{fileName, lineNumber, 1 + indent, offset + indent}
+		offset += len(line)
+
+		logicalLine := strings.TrimLeft(strings.TrimRight(line, "\r\n"), " \t\f")
+		isComment := strings.HasPrefix(logicalLine, "#") || strings.HasPrefix(logicalLine, "!")
+		// A line ending with an odd number of backslashes continues on the next line
+		for !isComment && err == nil && (len(logicalLine)-len(strings.TrimRight(logicalLine, `\`)))%2 == 1 {
+			line, err = bufReader.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			lineNumber++
+			offset += len(line)
+			logicalLine = logicalLine[:len(logicalLine)-1] + strings.TrimLeft(strings.TrimRight(line, "\r\n"), " \t\f")
+		}
+
+		if logicalLine != "" && !isComment {
+			key, value := splitProperty(logicalLine)
+			if key, err = unescapeProperty(key, location); err != nil {
+				return nil, err
+			}
+			if value, err = unescapeProperty(value, location); err != nil {
+				return nil, err
+			}
+			setProperty(root, strings.Split(key, "."), newValueNode(HoconToken{Text, value, "", location}))
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+	return root, nil
+}
+
+// splitProperty splits a logical line at the first unescaped '=', ':' or whitespace separating the key from the value
+func splitProperty(line string) (key, value string) {
+	i := 0
+	for ; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			break
+		}
+	}
+	if i > len(line) {
+		i = len(line)
+	}
+	key = line[:i]
+	value = strings.TrimLeft(line[i:], " \t\f")
+	if value != "" && (value[0] == '=' || value[0] == ':') {
+		value = strings.TrimLeft(value[1:], " \t\f")
+	}
+	return key, value
+}
+
+// unescapeProperty replaces the escape sequences of a properties key or value, such as \t, \n or \uXXXX
+func unescapeProperty(s string, location LexLocation) (string, error) {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s, nil
+	}
+	var builder strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			builder.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			builder.WriteByte('\t')
+		case 'n':
+			builder.WriteByte('\n')
+		case 'r':
+			builder.WriteByte('\r')
+		case 'f':
+			builder.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", &PropertiesMalformedEscapeErr{location}
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", &PropertiesMalformedEscapeErr{location}
+			}
+			builder.WriteRune(rune(r))
+			i += 4
+		default:
+			builder.WriteByte(s[i])
+		}
+	}
+	return builder.String(), nil
+}
+
+// setProperty assigns the value to the path below the root, replacing any values in the way with objects
+// but never replacing an object with a value
+func setProperty(root *hoconNode, path []string, value *hoconNode) {
+	node := root
+	for _, key := range path[:len(path)-1] {
+		child, ok := node.fields[key]
+		if !ok || child.nodeType != objectNode {
+			child = newObjectNode(value.LexLocation)
+			node.setField(key, child)
+		}
+		node = child
+	}
+	key := path[len(path)-1]
+	if existing, ok := node.fields[key]; ok && existing.nodeType == objectNode {
+		return
+	}
+	node.setField(key, value)
+}
@@ -0,0 +1,176 @@
+package aconf
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type legacyConfig struct {
+	Axlrate struct {
+		Imdg struct {
+			Name        string   `hocon:"name"`
+			Hosts       []string `hocon:"hosts"`
+			Description string   `hocon:"description"`
+			Greeting    string   `hocon:"greeting"`
+			Mode        struct {
+				Fallback string `hocon:"fallback"`
+			} `hocon:"mode"`
+			Timeout time.Duration `hocon:"timeout"`
+		} `hocon:"imdg"`
+	} `hocon:"axlrate"`
+}
+
+func TestParseProperties(t *testing.T) {
+	reader, err := os.Open("test_data/legacy.properties")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	target := &legacyConfig{}
+	parser := &HoconParser{}
+	if err := parser.ParseProperties(reader, target); err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+	imdg := target.Axlrate.Imdg
+	if imdg.Name != "axlrate-imdg" || !reflect.DeepEqual(imdg.Hosts, []string{"10.0.0.1", "10.0.0.2"}) {
+		t.Errorf("Got: %+v", imdg)
+	}
+	if imdg.Description != "a long description" || imdg.Greeting != "café\tbar" || imdg.Mode.Fallback != "remote" {
+		t.Errorf("Got: %+v", imdg)
+	}
+}
+
+var propertiesTests = []struct {
+	contents string
+	want     map[string]interface{}
+}{
+	{`a=b`, map[string]interface{}{"a": "b"}},
+	{`a = b`, map[string]interface{}{"a": "b"}},
+	{`a : b`, map[string]interface{}{"a": "b"}},
+	{`a b`, map[string]interface{}{"a": "b"}},
+	{`a==b`, map[string]interface{}{"a": "=b"}},
+	{`a\ b=c`, map[string]interface{}{"a b": "c"}},
+	{`a`, map[string]interface{}{"a": ""}},
+	{`a.`, map[string]interface{}{"a": map[string]interface{}{"": ""}}},
+	{"a=hello\na.b=world", map[string]interface{}{"a": map[string]interface{}{"b": "world"}}},
+	{"a.b=world\na=hello", map[string]interface{}{"a": map[string]interface{}{"b": "world"}}},
+	{"a=10\r\n#b=20\r\n", map[string]interface{}{"a": "10"}},
+}
+
+func TestPropertiesMapping(t *testing.T) {
+	for _, test := range propertiesTests {
+		got := map[string]interface{}{}
+		parser := &HoconParser{}
+		if err := parser.ParseProperties(strings.NewReader(test.contents), &got); err != nil {
+			t.Errorf("input: %q, failed with non-nil error : %v", test.contents, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("input: %q, Got: %v, Want: %v", test.contents, got, test.want)
+		}
+	}
+}
+
+func TestMalformedPropertiesEscape(t *testing.T) {
+	parser := &HoconParser{}
+	err := parser.ParseProperties(strings.NewReader("a=1\nb=\\u12"), &map[string]interface{}{})
+	if e, ok := err.(*PropertiesMalformedEscapeErr); !ok || e.lineNumber != 2 {
+		t.Errorf("Expected : %v, Got : %v", &PropertiesMalformedEscapeErr{LexLocation{lineNumber: 2, columnNumber: 1}}, err)
+	}
+}
+
+func TestIncludeProperties(t *testing.T) {
+	reader, err := os.Open("test_data/hocon.include.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	target := &legacyConfig{}
+	parser := &HoconParser{}
+	if err := parser.Parse(reader, target); err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+	imdg := target.Axlrate.Imdg
+	if imdg.Name != "axlrate-imdg" || imdg.Timeout != 10*time.Second || imdg.Description != "from the conf file" || len(imdg.Hosts) != 2 {
+		t.Errorf("Got: %+v", imdg)
+	}
+}
+
+func TestIncludeRelativeToIncludingFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"conf/app.conf":           `include "base.conf"`,
+		"conf/base.conf":          "a = 1\ninclude \"shared/common.conf\"",
+		"conf/shared/common.conf": `b = 2`,
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reader, err := os.Open(filepath.Join(dir, "conf/app.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	got := map[string]interface{}{}
+	parser := &HoconParser{}
+	if err := parser.Parse(reader, &got); err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+	want := map[string]interface{}{"a": int64(1), "b": int64(2)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got: %v, Want: %v", got, want)
+	}
+}
+
+func TestIncludeResolver(t *testing.T) {
+	files := map[string]string{
+		"base.conf":       `a { x = 1, y = 2 }`,
+		"cycle.conf":      `include "cycle.conf"`,
+		"legacy.json":     `a { z = "json" }`,
+		"legacy.conf":     `a { z = "conf" }`,
+		"unbalanced.conf": `a {`,
+	}
+	parser := &HoconParser{IncludeResolver: func(name string) (io.ReadCloser, error) {
+		contents, ok := files[name]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return ioutil.NopCloser(strings.NewReader(contents)), nil
+	}}
+
+	got := map[string]interface{}{}
+	contents := `
+	a { x = 0 }
+	include required("base.conf")
+	a.y = 3
+	include "legacy"
+	`
+	if err := parser.Parse(strings.NewReader(contents), &got); err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+	want := map[string]interface{}{"a": map[string]interface{}{"x": int64(1), "y": int64(3), "z": "conf"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got: %v, Want: %v", got, want)
+	}
+
+	for _, contents := range []string{`include required("missing.conf")`, `include "cycle.conf"`, `include "unbalanced.conf"`, `include url("http://localhost/a.conf")`} {
+		err := parser.Parse(strings.NewReader(contents), &got)
+		var includeErr *ParserIncludeErr
+		var invalidErr *ParserInvalidIncludeErr
+		if !errors.As(err, &includeErr) && !errors.As(err, &invalidErr) {
+			t.Errorf("input: %v, Expected an include error, Got : %v", contents, err)
+		}
+	}
+}
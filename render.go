@@ -0,0 +1,170 @@
+package aconf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RenderOptions controls how a Config is rendered as HOCON
+type RenderOptions struct {
+	// Origins adds a comment such as # from app.conf:12:5 before each field, and before each element of the arrays
+	// which are rendered on several lines, telling where it was set
+	Origins bool
+	// Indent is the indentation of each level of nesting, two spaces if empty
+	Indent string
+}
+
+// Render returns the Config as HOCON text, which parses back into the same values.
+// The fields of objects are rendered in the order in which they were first set.
+func (config *Config) Render(options RenderOptions) string {
+	if options.Indent == "" {
+		options.Indent = "  "
+	}
+	r := &renderer{options: options}
+	switch {
+	case config.root == nil:
+	case config.root.nodeType == objectNode:
+		r.fields(config.root, 0)
+	default:
+		r.comment(config.root, 0)
+		r.value(config.root, 0)
+		r.WriteByte('\n')
+	}
+	return r.String()
+}
+
+type renderer struct {
+	strings.Builder
+	options RenderOptions
+}
+
+// fields writes the fields of the object node, one per line
+func (r *renderer) fields(node *hoconNode, depth int) {
+	for _, key := range node.keys {
+		field := node.fields[key]
+		r.comment(field, depth)
+		r.indent(depth)
+		r.WriteString(joinPath("", key))
+		if field.nodeType == objectNode {
+			r.WriteByte(' ')
+		} else {
+			r.WriteString(" = ")
+		}
+		r.value(field, depth)
+		r.WriteByte('\n')
+	}
+}
+
+// value writes the value of the node, which starts on the current line and ends without a newline
+func (r *renderer) value(node *hoconNode, depth int) {
+	switch node.nodeType {
+	case objectNode:
+		if len(node.keys) == 0 {
+			r.WriteString("{}")
+			return
+		}
+		r.WriteString("{\n")
+		r.fields(node, depth+1)
+		r.indent(depth)
+		r.WriteByte('}')
+	case arrayNode:
+		if !r.multiline(node) {
+			r.WriteByte('[')
+			for i, element := range node.elements {
+				if i > 0 {
+					r.WriteString(", ")
+				}
+				r.value(element, depth+1)
+			}
+			r.WriteByte(']')
+			return
+		}
+		r.WriteString("[\n")
+		for _, element := range node.elements {
+			r.comment(element, depth+1)
+			r.indent(depth + 1)
+			r.value(element, depth+1)
+			r.WriteByte('\n')
+		}
+		r.indent(depth)
+		r.WriteByte(']')
+	default:
+		r.WriteString(renderToken(node.token))
+	}
+}
+
+// multiline reports whether the elements of the array node are rendered on lines of their own, which they are if
+// any of them is an object or an array, or to give their origins
+func (r *renderer) multiline(node *hoconNode) bool {
+	if len(node.elements) == 0 {
+		return false
+	}
+	if r.options.Origins {
+		return true
+	}
+	for _, element := range node.elements {
+		if element.nodeType != valueNode {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *renderer) comment(node *hoconNode, depth int) {
+	if r.options.Origins && node.Line() > 0 {
+		r.indent(depth)
+		r.WriteString("# from " + node.origin().String() + "\n")
+	}
+}
+
+func (r *renderer) indent(depth int) {
+	for i := 0; i < depth; i++ {
+		r.WriteString(r.options.Indent)
+	}
+}
+
+// renderToken returns the text of a value, in a form which the lexer reads back as a value of the same type
+func renderToken(token HoconToken) string {
+	switch token.Type {
+	case Integer, Float, Boolean:
+		return token.Value
+	case Size:
+		return token.Value + "B"
+	case Duration:
+		// Durations are held in nanoseconds, which are rendered the way time.Duration prints them if possible
+		if n, err := strconv.ParseInt(token.Value, 10, 64); err == nil && n >= 0 {
+			return time.Duration(n).String()
+		}
+		return token.Value + "ns"
+	case TimePeriod:
+		return strings.Join(strings.Fields(token.Value), "")
+	}
+	return quote(token.Value)
+}
+
+// quote returns the string as a JSON string literal
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r < 0x20 || r == 0x7F:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,96 @@
+package aconf
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const renderContents = `name = "app \"one\""
+"akka.actor" = local
+timeout = 90s
+cache { size = 2 MB, ttl = 2 months }
+include "servers.conf"
+ports = [80, 443]
+home = ${HOME}
+port = ${ports.0}
+`
+
+func renderParser() *HoconParser {
+	return &HoconParser{
+		LookupEnv: func(key string) (string, bool) { return "/home/app", key == "HOME" },
+		IncludeResolver: func(name string) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("servers = [\n  { host = a }\n  { host = b, tags = [x] }\n]")), nil
+		},
+	}
+}
+
+func TestRender(t *testing.T) {
+	config, err := renderParser().ParseConfig(&namedReader{strings.NewReader(renderContents), "app.conf"})
+	if err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+	want := `name = "app \"one\""
+"akka.actor" = "local"
+timeout = 1m30s
+cache {
+  size = 2097152B
+  ttl = 2months
+}
+servers = [
+  {
+    host = "a"
+  }
+  {
+    host = "b"
+    tags = ["x"]
+  }
+]
+ports = [80, 443]
+home = "/home/app"
+port = 80
+`
+	got := config.Render(RenderOptions{})
+	if got != want {
+		t.Errorf("Got: %v, Want : %v", got, want)
+	}
+
+	// The rendered text parses back into the same values
+	var original, rendered map[string]interface{}
+	if err := config.Decode("", &original); err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+	parser := &HoconParser{}
+	if err := parser.Parse(strings.NewReader(got), &rendered); err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+	if !reflect.DeepEqual(rendered, original) {
+		t.Errorf("Got: %v, Want : %v", rendered, original)
+	}
+}
+
+func TestRenderOrigins(t *testing.T) {
+	config, err := renderParser().ParseConfig(&namedReader{strings.NewReader(renderContents), "app.conf"})
+	if err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+	got := config.Render(RenderOptions{Origins: true, Indent: "\t"})
+	for _, want := range []string{
+		"# from app.conf:1:8\nname = ",
+		"\t# from app.conf:4:28\n\tttl = 2months\n",
+		"# from servers.conf:1:11 (include)\nservers = [\n\t# from servers.conf:2:3 (include)\n\t{\n",
+		"ports = [\n\t# from app.conf:6:10\n\t80\n",
+		"# from app.conf:7:8 (env)\nhome = ",
+		"# from app.conf:8:8 (substitution)\nport = 80\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Got: %v, Want : to contain %q", got, want)
+		}
+	}
+	var rendered map[string]interface{}
+	parser := &HoconParser{}
+	if err := parser.Parse(strings.NewReader(got), &rendered); err != nil {
+		t.Errorf("failed with non-nil error : %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package aconf
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// ANSI escape codes used by the ErrorFormatter in color mode
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiFaint = "\x1b[2m"
+	ansiRed   = "\x1b[1;31m"
+)
+
+// ErrorFormatter formats errors the way compilers do, with the line of the source in which the error was found and
+// a ^ under its column, along with the lines before and after it:
+//
+//	app.conf:3:8: lexer: Invalid Token @
+//	  2 | name = "axlrate"
+//	  3 | port = @8080
+//	    |        ^
+//	  4 | retries = 3
+//
+// Errors without a location, or whose source cannot be read, are formatted as their message only.
+type ErrorFormatter struct {
+	// Color highlights the message and the ^ using ANSI escape codes, for printing to a terminal
+	Color bool
+	// Source returns the contents of the named file. If nil, the file is read using ioutil.ReadFile.
+	// Errors in input not read from a named file have an empty file name.
+	Source func(fileName string) ([]byte, error)
+}
+
+// Format returns the error with the source snippet for its location.
+// Each of the errors in an ErrorList is formatted in turn, separated by blank lines. Each source file is read once.
+func (f *ErrorFormatter) Format(err error) string {
+	return f.format(err, map[string][]string{})
+}
+
+// format formats the error, keeping the lines of the source files read in sources
+func (f *ErrorFormatter) format(err error, sources map[string][]string) string {
+	var list ErrorList
+	if errors.As(err, &list) {
+		snippets := make([]string, len(list))
+		for i, e := range list {
+			snippets[i] = f.format(e, sources)
+		}
+		return strings.Join(snippets, "\n\n")
+	}
+
+	msg := err.Error()
+	if f.Color {
+		msg = ansiBold + msg + ansiReset
+	}
+	located, ok := err.(interface{ Location() LexLocation })
+	if !ok {
+		return msg
+	}
+	location := located.Location()
+	lines, read := sources[location.fileName]
+	if !read {
+		lines = f.sourceLines(location.fileName)
+		sources[location.fileName] = lines
+	}
+	if location.lineNumber < 1 || location.lineNumber > len(lines) {
+		return msg
+	}
+
+	var builder strings.Builder
+	builder.WriteString(msg)
+	first, last := location.lineNumber-1, location.lineNumber+1
+	if first < 1 {
+		first = 1
+	}
+	if last > len(lines) {
+		last = len(lines)
+	}
+	width := len(strconv.Itoa(last))
+	for n := first; n <= last; n++ {
+		line := lines[n-1]
+		builder.WriteString("\n")
+		builder.WriteString(f.gutter(fmt.Sprintf("%*d", width, n)))
+		builder.WriteString(line)
+		if n == location.lineNumber {
+			builder.WriteString("\n")
+			builder.WriteString(f.gutter(strings.Repeat(" ", width)))
+			builder.WriteString(caretPadding(line, location.columnNumber))
+			if f.Color {
+				builder.WriteString(ansiRed + "^" + ansiReset)
+			} else {
+				builder.WriteString("^")
+			}
+		}
+	}
+	return builder.String()
+}
+
+// gutter returns the line number column in front of a source line
+func (f *ErrorFormatter) gutter(lineNumber string) string {
+	if f.Color {
+		return ansiFaint + "  " + lineNumber + " | " + ansiReset
+	}
+	return "  " + lineNumber + " | "
+}
+
+// sourceLines returns the lines of the named file, or nil if it cannot be read
+func (f *ErrorFormatter) sourceLines(fileName string) []string {
+	source := f.Source
+	if source == nil {
+		if fileName == "" {
+			return nil
+		}
+		source = ioutil.ReadFile
+	}
+	contents, err := source(fileName)
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(string(contents), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// caretPadding returns the whitespace which puts a ^ under the column of the line, keeping any tabs so that
+// the ^ lines up however wide tabs are displayed
+func caretPadding(line string, column int) string {
+	var builder strings.Builder
+	n := 1
+	for _, r := range line {
+		if n >= column {
+			break
+		}
+		if r == '\t' {
+			builder.WriteRune('\t')
+		} else {
+			builder.WriteRune(' ')
+		}
+		n++
+	}
+	for ; n < column; n++ {
+		builder.WriteRune(' ')
+	}
+	return builder.String()
+}
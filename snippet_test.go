@@ -0,0 +1,65 @@
+package aconf
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestErrorFormatter(t *testing.T) {
+	contents := "name = \"axlrate\"\nport = @8080\n\tretries = [1, 2\n"
+	reads := 0
+	source := func(string) ([]byte, error) {
+		reads++
+		return []byte(contents), nil
+	}
+	parser := &HoconParser{}
+	err := parser.Parse(strings.NewReader(contents), &map[string]interface{}{})
+	if err == nil {
+		t.Fatal("Expected : errors, Got : nil")
+	}
+
+	f := &ErrorFormatter{Source: source}
+	want := "2:8: lexer: Invalid Token @\n" +
+		"  1 | name = \"axlrate\"\n" +
+		"  2 | port = @8080\n" +
+		"    |        ^\n" +
+		"  3 | \tretries = [1, 2\n\n" +
+		"3:12: Unbalanced Parentheses\n" +
+		"  2 | port = @8080\n" +
+		"  3 | \tretries = [1, 2\n" +
+		"    | \t          ^\n" +
+		"  4 | "
+	if got := f.Format(err); got != want {
+		t.Errorf("Got: %q, Want : %q", got, want)
+	}
+	if reads != 1 {
+		t.Errorf("Expected : the source to be read once, Got : %d reads", reads)
+	}
+
+	f.Color = true
+	if got := f.Format(err); !strings.Contains(got, ansiRed+"^"+ansiReset) || !strings.HasPrefix(got, ansiBold+"2:8: lexer: Invalid Token @"+ansiReset) {
+		t.Errorf("Got: %q, Want : ANSI escapes", got)
+	}
+}
+
+func TestErrorFormatterReadsNamedFiles(t *testing.T) {
+	reader, err := os.Open("test_data/hocon.unbalanced.paren.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	parser := &HoconParser{}
+	err = parser.Parse(reader, &map[string]interface{}{})
+	got := (&ErrorFormatter{}).Format(err)
+	if !strings.HasPrefix(got, "test_data/hocon.unbalanced.paren.conf:2:9: Unbalanced Parentheses\n  1 | name = \"axlrate-imdg\"\n  2 | axlrate { # Main block\n    |         ^\n") {
+		t.Errorf("Got: %q", got)
+	}
+
+	// Without a location or a source, only the message is returned
+	for _, e := range []error{&ParserInvalidTargetErr{"int", "ptr"}, &LexScannerErr{"literal not terminated", LexLocation{lineNumber: 1, columnNumber: 1}}} {
+		if got := (&ErrorFormatter{}).Format(e); got != e.Error() {
+			t.Errorf("Got: %q, Want : %q", got, e.Error())
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package aconf
+
+import "strings"
+
+// resolveSubstitutions replaces the substitutions in the tree, such as ${a.b} or ${HOME}, with the values they refer
+// to. A path which is set in the tree is replaced by a copy of its value, otherwise the environment variable of that
+// name is looked up, whose value is a string.
+//
+// The field or array element of an optional substitution such as ${?HOME}, which cannot be resolved, is left out.
+// Substitutions which cannot be resolved, or which refer to themselves, are added to the parser's errors.
+func (parser *parseState) resolveSubstitutions(root *hoconNode) {
+	parser.resolve(root, root, map[*hoconNode]bool{})
+}
+
+// resolve returns the node with the substitutions below it resolved, or nil if it is a substitution which is left out.
+// Objects and arrays are resolved in place. The substitutions which are being resolved are held in resolving
+func (parser *parseState) resolve(root, node *hoconNode, resolving map[*hoconNode]bool) *hoconNode {
+	switch {
+	case node.nodeType == objectNode:
+		keys := node.keys[:0]
+		for _, key := range node.keys {
+			field := parser.resolve(root, node.fields[key], resolving)
+			if field == nil {
+				delete(node.fields, key)
+				continue
+			}
+			node.fields[key] = field
+			keys = append(keys, key)
+		}
+		node.keys = keys
+	case node.nodeType == arrayNode:
+		elements := node.elements[:0]
+		for _, element := range node.elements {
+			if element = parser.resolve(root, element, resolving); element != nil {
+				elements = append(elements, element)
+			}
+		}
+		node.elements = elements
+	case node.token.Type == Substitution:
+		return parser.resolveSubstitution(root, node, resolving)
+	}
+	return node
+}
+
+// resolveSubstitution returns the value which the substitution node refers to, or nil if it is left out
+func (parser *parseState) resolveSubstitution(root, node *hoconNode, resolving map[*hoconNode]bool) *hoconNode {
+	token := node.token
+	if resolving[node] {
+		parser.errs.add(&LexUnresolvedSubstitutionErr{token.Value, token.LexLocation})
+		return nil
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(token.Value, "${"), "}")
+	optional := strings.HasPrefix(name, "?")
+	name = strings.TrimSpace(strings.TrimPrefix(name, "?"))
+
+	if path, err := splitPath(name); err == nil {
+		resolving[node] = true
+		target := parser.lookup(root, path, resolving)
+		if target != nil {
+			target = parser.resolve(root, target, resolving)
+		}
+		delete(resolving, node)
+		if target != nil {
+			// The copy is reported at the substitution, rather than where the value it copies was set
+			value := target.clone()
+			value.setSource(OriginSubstitution)
+			value.LexLocation, value.source = node.LexLocation, OriginSubstitution
+			return value
+		}
+	}
+	if parser.LookupEnv != nil {
+		if value, ok := parser.LookupEnv(name); ok {
+			env := newValueNode(HoconToken{Text, value, "", token.LexLocation})
+			env.source = OriginEnv
+			return env
+		}
+	}
+	if !optional {
+		parser.errs.add(&LexUnresolvedSubstitutionErr{token.Value, token.LexLocation})
+	}
+	return nil
+}
+
+// lookup returns the node at the path below the root, or nil if there is none. The substitutions on the way to it
+// are resolved, so that a path can go through a substitution which refers to an object or an array
+func (parser *parseState) lookup(root *hoconNode, path []string, resolving map[*hoconNode]bool) *hoconNode {
+	node := root
+	for _, key := range path {
+		if node = node.get([]string{key}); node == nil {
+			return nil
+		}
+		if node.nodeType == valueNode && node.token.Type == Substitution {
+			if node = parser.resolveSubstitution(root, node, resolving); node == nil {
+				return nil
+			}
+		}
+	}
+	return node
+}
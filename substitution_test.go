@@ -0,0 +1,92 @@
+package aconf
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSubstitutions(t *testing.T) {
+	contents := `
+	a = 1
+	b = ${a}
+	c = ${d.e}
+	d { e = text, f = [1, 2] }
+	g = ${d}
+	h = ${g.f}
+	i = [${a}, ${?MISSING}, ${HOME}]
+	j = ${?MISSING}
+	k = ${?a}
+	`
+	parser := &HoconParser{LookupEnv: func(key string) (string, bool) {
+		if key == "HOME" || key == "a" {
+			return "/home/" + key, true
+		}
+		return "", false
+	}}
+	m := map[string]interface{}{}
+	if err := parser.Parse(strings.NewReader(contents), &m); err != nil {
+		t.Fatalf("failed with non-nil error : %v", err)
+	}
+	d := map[string]interface{}{"e": "text", "f": []interface{}{int64(1), int64(2)}}
+	want := map[string]interface{}{
+		"a": int64(1), "b": int64(1), "c": "text", "d": d, "g": d, "h": []interface{}{int64(1), int64(2)},
+		"i": []interface{}{int64(1), "/home/HOME"}, "k": int64(1),
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("Got: %v, Want : %v", m, want)
+	}
+}
+
+func TestSubstitutionsOfPathsThroughSubstitutions(t *testing.T) {
+	var target struct {
+		X string `hocon:"x"`
+	}
+	if err := Unmarshal([]byte("x = ${a.b}\na = ${c}\nc { b = found }"), &target); err != nil || target.X != "found" {
+		t.Errorf("Got: %v, %v, Want : found", target.X, err)
+	}
+}
+
+func TestUnresolvedSubstitutions(t *testing.T) {
+	tests := []struct {
+		contents string
+		want     []string
+	}{
+		{"a = ${missing}", []string{"${missing}"}},
+		{"a = ${a}", []string{"${a}"}},
+		{"a = ${b}\nb = ${a}", []string{"${b}", "${a}"}},
+		{"a { b = ${a} }", []string{"${a}"}},
+	}
+	for _, test := range tests {
+		err := Unmarshal([]byte(test.contents), &map[string]interface{}{})
+		var got []string
+		var list ErrorList
+		if !errors.As(err, &list) {
+			list = ErrorList{err}
+		}
+		for _, e := range list {
+			var substitutionErr *LexUnresolvedSubstitutionErr
+			if errors.As(e, &substitutionErr) {
+				got = append(got, substitutionErr.Expression)
+			}
+		}
+		if !reflect.DeepEqual(got, test.want) || !errors.Is(err, ErrUnresolvedSubstitution) {
+			t.Errorf("input: %q, Got: %v, Want : %v", test.contents, err, test.want)
+		}
+	}
+}
+
+func TestSubstitutionsInIncludedFiles(t *testing.T) {
+	var target struct {
+		URL string `hocon:"url"`
+	}
+	dec := NewDecoder(strings.NewReader("include \"server.conf\"\nhost = example.com")).
+		WithIncludeResolver(func(name string) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("url = ${host}")), nil
+		})
+	if err := dec.Decode(&target); err != nil || target.URL != "example.com" {
+		t.Errorf("Got: %v, %v, Want : example.com", target.URL, err)
+	}
+}
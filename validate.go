@@ -0,0 +1,216 @@
+package aconf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Validator is implemented by types which check their own values once they are decoded.
+//
+// Validate is called on every struct decoded from an object, after its fields are decoded and have passed the
+// constraints in their validate tags. It is also called on a nested struct whose key is not set, which holds its
+// zero value. The error it returns is reported as a *ParserValidationErr for the object.
+type Validator interface {
+	Validate() error
+}
+
+var validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+
+// function validateStruct checks the fields of the struct v, decoded from the object node, against the constraints
+// in their validate tags and then calls its Validate method, adding the errors to the parser's errors.
+// Fields whose keys are in failed could not be decoded, so they are not checked again.
+//
+// The constraints in a validate tag are separated by commas:
+//
+//	required      the key must be set
+//	nonempty      the value must not be zero, or a string, slice or map must not be empty
+//	min=N, max=N  bounds of a number, or of the length of a string, slice or map. For a time.Duration, N is a duration
+//	oneof=a b c   the value must be one of the words separated by spaces
+//	match=RE      a string must match the regular expression, which takes the rest of the tag so it may contain commas
+func (parser *parseState) validateStruct(node *hoconNode, v reflect.Value, path string, failed map[string]bool) {
+	for _, sf := range reflect.VisibleFields(v.Type()) {
+		tag, ok := sf.Tag.Lookup("validate")
+		if !ok || sf.Anonymous || !sf.IsExported() || failed[sf.Name] {
+			continue
+		}
+		key, field := sf.Name, node.fields[sf.Name]
+		if name, _ := hoconTag(sf); name != "" && len(sf.Index) == 1 && field == nil {
+			key, field = name, node.fields[name]
+			if failed[key] {
+				continue
+			}
+		}
+		fv, err := v.FieldByIndexErr(sf.Index)
+		if err != nil {
+			fv = reflect.Zero(sf.Type)
+		}
+		if err := checkConstraints(tag, fv, field != nil); err != nil {
+			if field == nil {
+				parser.errs.add(&ParserMissingKeyErr{joinPath(path, key), node.LexLocation})
+			} else {
+				parser.errs.add(&ParserValidationErr{joinPath(path, key), err, field.LexLocation})
+			}
+		}
+	}
+
+	if v.CanAddr() && reflect.PtrTo(v.Type()).Implements(validatorType) {
+		if err := v.Addr().Interface().(Validator).Validate(); err != nil {
+			parser.errs.add(&ParserValidationErr{path, err, node.LexLocation})
+		}
+	}
+}
+
+// function validateUnset validates the struct fields of v which none of the keys of the object node are decoded
+// into, as if they were decoded from empty objects, so that the required keys of a nested struct are reported even if
+// the key of the struct itself is not set. Pointers to structs are left nil, so they are not validated.
+func (parser *parseState) validateUnset(node *hoconNode, v reflect.Value, path string) {
+	for _, sf := range reflect.VisibleFields(v.Type()) {
+		if sf.Anonymous || !sf.IsExported() || !isNestedStruct(sf.Type) {
+			continue
+		}
+		if _, options := hoconTag(sf); containsString(options, "remain") {
+			continue
+		}
+		key, set := fieldKey(node, sf)
+		if set {
+			continue
+		}
+		fv, err := v.FieldByIndexErr(sf.Index)
+		if err != nil {
+			fv = reflect.New(sf.Type).Elem()
+		}
+		empty := newObjectNode(node.LexLocation)
+		parser.validateUnset(empty, fv, joinPath(path, key))
+		parser.validateStruct(empty, fv, joinPath(path, key), nil)
+	}
+}
+
+// isNestedStruct reports whether values of the type are decoded from objects into the fields of a struct
+func isNestedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && t != periodType && t != configType &&
+		!reflect.PtrTo(t).Implements(unmarshalerType)
+}
+
+// checkConstraints checks the value against the constraints of a validate tag, returning the first one it fails.
+// A value which is not set only fails the required constraint, which returns errMissing
+func checkConstraints(tag string, v reflect.Value, set bool) error {
+	for tag != "" {
+		var rule string
+		if strings.HasPrefix(tag, "match=") {
+			rule, tag = tag, ""
+		} else {
+			rule, tag, _ = strings.Cut(tag, ",")
+		}
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		if name == "required" {
+			if !set {
+				return errMissing
+			}
+			continue
+		}
+		if !set {
+			continue
+		}
+		if err := checkConstraint(name, arg, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var errMissing = errors.New("is not set")
+
+// checkConstraint checks the value against a single constraint, other than required
+func checkConstraint(name, arg string, v reflect.Value) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			if name == "nonempty" {
+				return errors.New("must not be empty")
+			}
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch name {
+	case "nonempty":
+		if n, ok := length(v); (ok && n == 0) || (!ok && v.IsZero()) {
+			return errors.New("must not be empty")
+		}
+	case "min", "max":
+		bound, err := parseBound(arg, v.Type())
+		if err != nil {
+			return fmt.Errorf("invalid constraint %s=%s", name, arg)
+		}
+		what := "value"
+		n, ok := length(v)
+		if ok {
+			what = "length"
+		} else if n, ok = number(v); !ok {
+			return fmt.Errorf("constraint %s does not apply to %s", name, v.Type())
+		}
+		if name == "min" && n < bound {
+			return fmt.Errorf("%s must be at least %s", what, arg)
+		}
+		if name == "max" && n > bound {
+			return fmt.Errorf("%s must be at most %s", what, arg)
+		}
+	case "oneof":
+		words := strings.Fields(arg)
+		s := fmt.Sprint(v.Interface())
+		for _, word := range words {
+			if s == word {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(words, ", "))
+	case "match":
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return fmt.Errorf("invalid constraint match=%s", arg)
+		}
+		if v.Kind() != reflect.String {
+			return fmt.Errorf("constraint match does not apply to %s", v.Type())
+		}
+		if !re.MatchString(v.String()) {
+			return fmt.Errorf("must match %s", arg)
+		}
+	default:
+		return fmt.Errorf("unknown constraint %s", name)
+	}
+	return nil
+}
+
+// parseBound parses the argument of a min or max constraint, which is a duration for a time.Duration
+func parseBound(arg string, t reflect.Type) (float64, error) {
+	if t == durationType {
+		d, err := ParseDuration(arg)
+		return float64(d), err
+	}
+	return strconv.ParseFloat(arg, 64)
+}
+
+// length returns the length of a string, slice, array or map
+func length(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), true
+	}
+	return 0, false
+}
+
+// number returns the value of a number
+func number(v reflect.Value) (float64, bool) {
+	switch {
+	case isIntKind(v.Kind()):
+		return float64(v.Int()), true
+	case isUintKind(v.Kind()):
+		return float64(v.Uint()), true
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
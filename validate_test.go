@@ -0,0 +1,132 @@
+package aconf
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type validatedServer struct {
+	Host    string        `hocon:"host" validate:"required,nonempty,match=^[a-z.]+(:[0-9]{1,5})?$"`
+	Port    int           `hocon:"port" validate:"min=1,max=65535"`
+	Mode    string        `hocon:"mode" validate:"oneof=active passive"`
+	Timeout time.Duration `hocon:"timeout" validate:"max=1m"`
+	Tags    []string      `hocon:"tags" validate:"max=2"`
+}
+
+type validatedConfig struct {
+	Name    string            `validate:"required"`
+	Servers []validatedServer `hocon:"servers"`
+	Primary string            `hocon:"primary"`
+}
+
+// Validate checks that the primary server is one of the servers
+func (c *validatedConfig) Validate() error {
+	for _, server := range c.Servers {
+		if server.Host == c.Primary {
+			return nil
+		}
+	}
+	return errors.New("primary is not one of the servers")
+}
+
+func TestValidation(t *testing.T) {
+	contents := `Name = app
+primary = a.example
+servers = [
+  { host = a.example, port = 80, mode = active, timeout = 10s, tags = [x] }
+]`
+	parser := &HoconParser{}
+	if err := parser.Parse(strings.NewReader(contents), &validatedConfig{}); err != nil {
+		t.Errorf("failed with non-nil error : %v", err)
+	}
+
+	contents = `primary = c.example
+servers = [
+  { host = a.example, port = 0, mode = backup }
+  { host = "B!", port = x, timeout = 2m, tags = [x, y, z] }
+  { port = 65536 }
+]`
+	err := parser.Parse(strings.NewReader(contents), &validatedConfig{})
+	want := []string{
+		"3:30: parser: invalid value for servers[0].port : value must be at least 1",
+		"3:40: parser: invalid value for servers[0].mode : must be one of active, passive",
+		"4:25: parser: cannot decode string servers[1].port into int",
+		"4:12: parser: invalid value for servers[1].host : must match ^[a-z.]+(:[0-9]{1,5})?$",
+		"4:38: parser: invalid value for servers[1].timeout : value must be at most 1m",
+		"4:49: parser: invalid value for servers[1].tags : length must be at most 2",
+		"5:3: parser: missing required key servers[2].host",
+		"5:12: parser: invalid value for servers[2].port : value must be at most 65535",
+		"1:1: parser: missing required key Name",
+		"1:1: parser: invalid config : primary is not one of the servers",
+	}
+	var list ErrorList
+	if !errors.As(err, &list) || len(list) != len(want) {
+		t.Fatalf("Got: %v, Want : %d errors", err, len(want))
+	}
+	for i, e := range list {
+		if e.Error() != want[i] {
+			t.Errorf("Got: %v, Want : %v", e, want[i])
+		}
+	}
+	if !errors.Is(err, ErrValidation) || !errors.Is(err, ErrMissingRequired) {
+		t.Errorf("Expected : %v and %v, Got : %v", ErrValidation, ErrMissingRequired, err)
+	}
+}
+
+func TestInvalidConstraints(t *testing.T) {
+	type TargetStruct struct {
+		A int    `validate:"min=one"`
+		B bool   `validate:"max=1"`
+		C string `validate:"between=1 2"`
+	}
+	parser := &HoconParser{}
+	err := parser.Parse(strings.NewReader("A = 1\nB = true\nC = x"), &TargetStruct{})
+	var list ErrorList
+	if !errors.As(err, &list) || len(list) != 3 {
+		t.Fatalf("Got: %v, Want : 3 errors", err)
+	}
+	for i, want := range []string{"invalid constraint min=one", "constraint max does not apply to bool", "unknown constraint between"} {
+		var validationErr *ParserValidationErr
+		if !errors.As(list[i], &validationErr) || validationErr.Err.Error() != want {
+			t.Errorf("Got: %v, Want : %v", list[i], want)
+		}
+	}
+}
+
+func TestValidationOfUnsetKeys(t *testing.T) {
+	type Srv struct {
+		Host string `hocon:"host" validate:"required"`
+	}
+	type TargetStruct struct {
+		Name string `hocon:"name" validate:"required"`
+		Db   Srv    `hocon:"db"`
+		Web  struct {
+			Backend Srv `hocon:"backend"`
+		} `hocon:"web"`
+		Cache *Srv `hocon:"cache"`
+	}
+	for _, contents := range []string{"", " \n\t\n", "# nothing but a comment"} {
+		err := Unmarshal([]byte(contents), &TargetStruct{})
+		var list ErrorList
+		if !errors.As(err, &list) || len(list) != 3 {
+			t.Fatalf("input: %q, Got: %v, Want : 3 errors", contents, err)
+		}
+		for i, want := range []string{"db.host", "web.backend.host", "name"} {
+			var missingErr *ParserMissingKeyErr
+			if !errors.As(list[i], &missingErr) || missingErr.Path != want {
+				t.Errorf("input: %q, Got: %v, Want : missing %v", contents, list[i], want)
+			}
+		}
+	}
+
+	err := Unmarshal([]byte("name = app\nweb { backend { host = b } }"), &TargetStruct{})
+	var missingErr *ParserMissingKeyErr
+	if !errors.As(err, &missingErr) || missingErr.Path != "db.host" || missingErr.Line() != 1 {
+		t.Errorf("Got: %v, Want : missing db.host on line 1", err)
+	}
+	if err := Unmarshal([]byte("name = app\ndb.host = a\nweb.backend.host = b"), &TargetStruct{}); err != nil {
+		t.Errorf("failed with non-nil error : %v", err)
+	}
+}